@@ -0,0 +1,98 @@
+package dcron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// BackfillOption represents a modification to the default behavior of Cron.Backfill.
+type BackfillOption func(o *backfillOptions)
+
+type backfillOptions struct {
+	parallelism int
+}
+
+// WithBackfillParallelism specifies how many tasks could run at the same time when backfilling,
+// parallelism will be set as 1 if it is less than 1.
+func WithBackfillParallelism(parallelism int) BackfillOption {
+	return func(o *backfillOptions) {
+		o.parallelism = parallelism
+	}
+}
+
+// Backfill runs the job for every plan time of its spec between from and to, both inclusive,
+// and returns the tasks in order of plan time.
+// Each task has a key distinct from the scheduled one, and only one instance will run it
+// if the same range is backfilled on multiple instances.
+// If the context is done or the cron is stopped, the tasks not started yet will be abandoned,
+// and the returned tasks are the started ones along with the error.
+func (c *Cron) Backfill(ctx context.Context, key string, from, to time.Time, options ...BackfillOption) ([]Task, error) {
+	j := c.job(key)
+	if j == nil {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, key)
+	}
+	if to.Before(from) {
+		return nil, errors.New("invalid range: to is before from")
+	}
+
+	o := &backfillOptions{}
+	for _, option := range options {
+		option(o)
+	}
+	if o.parallelism < 1 {
+		o.parallelism = 1
+	}
+
+	schedule, err := specParser.Parse(j.Spec())
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec %q: %w", j.Spec(), err)
+	}
+	var planAts []time.Time
+	for t := schedule.Next(from.In(c.location).Add(-time.Nanosecond)); !t.IsZero() && !t.After(to); t = schedule.Next(t) {
+		planAts = append(planAts, t)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(c.runningContext(), cancel)
+	defer stop()
+
+	tasks := make([]Task, len(planAts))
+	slots := make(chan struct{}, o.parallelism)
+	wg := sync.WaitGroup{}
+	started := 0
+	for _, planAt := range planAts {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, planAt time.Time) {
+			defer wg.Done()
+			defer func() {
+				<-slots
+			}()
+			tasks[i] = j.exec(ctx, Task{
+				Key:    fmt.Sprintf("dcron:%s.%s@%d/backfill", c.key, j.key, planAt.Unix()),
+				Cron:   c,
+				Job:    j,
+				PlanAt: planAt,
+				Manual: true,
+			})
+		}(started, planAt)
+		started++
+	}
+	wg.Wait()
+
+	if started < len(planAts) {
+		return tasks[:started], ctx.Err()
+	}
+	return tasks, nil
+}
@@ -0,0 +1,114 @@
+package dcron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gochore/dcron/mock_dcron"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestCron_Backfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	atomic := mock_dcron.NewMockAtomic(ctrl)
+
+	var mu sync.Mutex
+	acquired := map[string]bool{}
+	atomic.EXPECT().
+		SetIfNotExists(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, key, value string) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if acquired[key] {
+				return false
+			}
+			acquired[key] = true
+			return true
+		}).
+		AnyTimes()
+
+	c := NewCron(WithKey("test_cron"), WithAtomic(atomic), WithLocation(time.UTC))
+	running, maxRunning := 0, 0
+	if err := c.AddJobs(NewJob("test", "0 */10 * * * *", func(ctx context.Context) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2023, 10, 13, 1, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 10, 13, 2, 0, 0, 0, time.UTC)
+
+	t.Run("regular", func(t *testing.T) {
+		tasks, err := c.Backfill(context.Background(), "test", from, to, WithBackfillParallelism(3))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tasks) != 7 {
+			t.Fatalf("len(tasks) = %v, want 7", len(tasks))
+		}
+		for i, task := range tasks {
+			planAt := from.Add(time.Duration(i) * 10 * time.Minute)
+			if !task.PlanAt.Equal(planAt) {
+				t.Fatalf("PlanAt = %v, want %v", task.PlanAt, planAt)
+			}
+			if want := fmt.Sprintf("dcron:test_cron.test@%d/backfill", planAt.Unix()); task.Key != want {
+				t.Fatalf("Key = %v, want %v", task.Key, want)
+			}
+			if !task.Manual || task.Missed || task.Return != nil {
+				t.Fatalf("unexpected task: %+v", task)
+			}
+		}
+		if maxRunning > 3 {
+			t.Fatalf("maxRunning = %v, want at most 3", maxRunning)
+		}
+	})
+
+	t.Run("backfilled already", func(t *testing.T) {
+		tasks, err := c.Backfill(context.Background(), "test", from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, task := range tasks {
+			if !task.Missed {
+				t.Fatalf("should be missed: %+v", task)
+			}
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		tasks, err := c.Backfill(ctx, "test", from, to)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Backfill() error = %v, want %v", err, context.Canceled)
+		}
+		if len(tasks) != 0 {
+			t.Fatalf("len(tasks) = %v, want 0", len(tasks))
+		}
+	})
+
+	t.Run("wrong arguments", func(t *testing.T) {
+		if _, err := c.Backfill(context.Background(), "unknown", from, to); !errors.Is(err, ErrJobNotFound) {
+			t.Fatalf("Backfill() error = %v, want %v", err, ErrJobNotFound)
+		}
+		if _, err := c.Backfill(context.Background(), "test", to, from); err == nil {
+			t.Fatal("Backfill() should fail with invalid range")
+		}
+	})
+}
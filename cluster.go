@@ -0,0 +1,186 @@
+package dcron
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ClusterStatistics is statistics info merged from all instances of a cron.
+// Note that every instance counts all tasks in TotalTask, and counts tasks run by others in MissedTask,
+// so only PassedTask, FailedTask and runs are the true numbers of the cluster.
+type ClusterStatistics struct {
+	Total     Statistics            // Statistics of all jobs on all instances
+	Jobs      map[string]Statistics // Statistics of each job on all instances, by job key
+	Instances map[string]Statistics // Statistics of all jobs on each instance, by hostname
+}
+
+// staleIntervals is how many publish intervals statistics are kept in ClusterStatistics after published.
+const staleIntervals = 3
+
+// instanceExpiration is how long an instance is kept in the instances of the cron after it published statistics last time.
+const instanceExpiration = 24 * time.Hour
+
+// publishedStatistics is what an instance publishes to the store.
+type publishedStatistics struct {
+	Jobs        map[string]Statistics `json:"jobs"`
+	PublishedAt time.Time             `json:"published_at"`
+}
+
+func (c *Cron) instancesKey() string {
+	return fmt.Sprintf("dcron:%s/instances", c.key)
+}
+
+func (c *Cron) statisticsKey(hostname string) string {
+	return fmt.Sprintf("dcron:%s/statistics/%s", c.key, hostname)
+}
+
+// PublishStatistics publishes statistics of the cron's all jobs on the instance to the store,
+// so they could be merged by ClusterStatistics on any instance.
+func (c *Cron) PublishStatistics(ctx context.Context) error {
+	if c.store == nil {
+		return ErrNoStore
+	}
+
+	published := publishedStatistics{
+		Jobs:        map[string]Statistics{},
+		PublishedAt: time.Now(),
+	}
+	for _, j := range c.allJobs() {
+		published.Jobs[j.key] = j.statistics.snapshot()
+	}
+	data, err := json.Marshal(published)
+	if err != nil {
+		return err
+	}
+	if err := c.store.Set(ctx, c.statisticsKey(c.hostname), string(data)); err != nil {
+		return err
+	}
+	return c.register(ctx)
+}
+
+// ClusterStatistics merges statistics published by all instances of the cron.
+// If the interval of WithStatisticsPublishing is set, statistics published earlier than 3 intervals ago are skipped,
+// since the instances have probably exited.
+// Instances which have not published statistics for 24 hours are removed, so they are always skipped.
+func (c *Cron) ClusterStatistics(ctx context.Context) (ClusterStatistics, error) {
+	if c.store == nil {
+		return ClusterStatistics{}, ErrNoStore
+	}
+	maxAge := staleIntervals * c.publishInterval
+
+	instances, err := c.instances(ctx)
+	if err != nil {
+		return ClusterStatistics{}, err
+	}
+	ret := ClusterStatistics{
+		Jobs:      map[string]Statistics{},
+		Instances: map[string]Statistics{},
+	}
+	for _, hostname := range instances {
+		published, ok, err := c.published(ctx, hostname)
+		if err != nil {
+			return ClusterStatistics{}, err
+		}
+		if !ok || maxAge > 0 && time.Since(published.PublishedAt) > maxAge {
+			continue
+		}
+
+		instance := Statistics{}
+		for key, s := range published.Jobs {
+			ret.Jobs[key] = ret.Jobs[key].Add(s)
+			instance = instance.Add(s)
+		}
+		ret.Instances[hostname] = instance
+		ret.Total = ret.Total.Add(instance)
+	}
+	return ret, nil
+}
+
+func (c *Cron) instances(ctx context.Context) ([]string, error) {
+	value, ok, err := c.store.Get(ctx, c.instancesKey())
+	if err != nil || !ok {
+		return nil, err
+	}
+	var ret []string
+	if err := json.Unmarshal([]byte(value), &ret); err != nil {
+		return nil, fmt.Errorf("invalid instances: %w", err)
+	}
+	return ret, nil
+}
+
+func (c *Cron) published(ctx context.Context, hostname string) (publishedStatistics, bool, error) {
+	value, ok, err := c.store.Get(ctx, c.statisticsKey(hostname))
+	if err != nil || !ok {
+		return publishedStatistics{}, false, err
+	}
+	var ret publishedStatistics
+	if err := json.Unmarshal([]byte(value), &ret); err != nil {
+		return publishedStatistics{}, false, fmt.Errorf("invalid statistics of %s: %w", hostname, err)
+	}
+	return ret, true, nil
+}
+
+// register adds the instance to the instances of the cron in the store,
+// and removes expired instances with their statistics.
+// Since the store has no atomic update, instances registering at the same time may overwrite each other,
+// but they will be added back when they publish statistics next time.
+func (c *Cron) register(ctx context.Context) error {
+	instances, err := c.instances(ctx)
+	if err != nil {
+		return err
+	}
+
+	var kept []string
+	registered, changed := false, false
+	for _, hostname := range instances {
+		if hostname == c.hostname {
+			registered = true
+			kept = append(kept, hostname)
+			continue
+		}
+		published, ok, err := c.published(ctx, hostname)
+		if err != nil {
+			return err
+		}
+		if ok && time.Since(published.PublishedAt) <= instanceExpiration {
+			kept = append(kept, hostname)
+			continue
+		}
+		if err := c.store.Delete(ctx, c.statisticsKey(hostname)); err != nil {
+			return err
+		}
+		changed = true
+	}
+	if !registered {
+		kept = append(kept, c.hostname)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	data, err := json.Marshal(kept)
+	if err != nil {
+		return err
+	}
+	return c.store.Set(ctx, c.instancesKey(), string(data))
+}
+
+// publishStatistics publishes statistics periodically until the context is done,
+// and publishes once more after that.
+func (c *Cron) publishStatistics(ctx context.Context) {
+	ticker := time.NewTicker(c.publishInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = c.PublishStatistics(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			_ = c.PublishStatistics(ctx)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package dcron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gochore/dcron/mock_dcron"
+	"go.uber.org/mock/gomock"
+)
+
+// newMapStore returns a mocked Store keeping values in a map.
+func newMapStore(ctrl *gomock.Controller) *mock_dcron.MockStore {
+	var mu sync.Mutex
+	values := map[string]string{}
+
+	store := mock_dcron.NewMockStore(ctrl)
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string) (string, bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		v, ok := values[key]
+		return v, ok, nil
+	}).AnyTimes()
+	store.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key, value string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		values[key] = value
+		return nil
+	}).AnyTimes()
+	store.EXPECT().Delete(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(values, key)
+		return nil
+	}).AnyTimes()
+	return store
+}
+
+func TestCron_ClusterStatistics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := newMapStore(ctrl)
+	ctx := context.Background()
+
+	newCron := func(hostname string) *Cron {
+		c := NewCron(WithKey("test_cron"), WithHostname(hostname), WithStore(store))
+		if err := c.AddJobs(
+			NewJob("test1", "0 0 0 1 1 *", func(ctx context.Context) error {
+				return nil
+			}),
+			NewJob("test2", "0 0 0 1 1 *", func(ctx context.Context) error {
+				return errors.New("failed")
+			}),
+		); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	c1 := newCron("test_hostname1")
+	c2 := newCron("test_hostname2")
+
+	for _, key := range []string{"test1", "test2"} {
+		if _, err := c1.Trigger(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c2.Trigger(ctx, "test1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := c1.ClusterStatistics(ctx); err != nil || len(got.Instances) != 0 {
+		t.Fatalf("ClusterStatistics() = %+v, %v", got, err)
+	}
+
+	for _, c := range []*Cron{c1, c2, c1} {
+		if err := c.PublishStatistics(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := c2.ClusterStatistics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := c1.Statistics().Add(c2.Statistics()); got.Total != want {
+		t.Fatalf("Total = %+v, want %+v", got.Total, want)
+	}
+	if want := (Statistics{TotalTask: 2, PassedTask: 2, TotalRun: 2, PassedRun: 2}); got.Jobs["test1"] != want {
+		t.Fatalf("Jobs[test1] = %+v, want %+v", got.Jobs["test1"], want)
+	}
+	if want := (Statistics{TotalTask: 1, FailedTask: 1, TotalRun: 1, FailedRun: 1}); got.Jobs["test2"] != want {
+		t.Fatalf("Jobs[test2] = %+v, want %+v", got.Jobs["test2"], want)
+	}
+	if len(got.Instances) != 2 || got.Instances["test_hostname1"] != c1.Statistics() || got.Instances["test_hostname2"] != c2.Statistics() {
+		t.Fatalf("Instances = %+v", got.Instances)
+	}
+}
+
+func TestCron_ClusterStatistics_Stale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := newMapStore(ctrl)
+	ctx := context.Background()
+
+	c := NewCron(WithKey("test_cron"), WithHostname("test_hostname"), WithStore(store))
+	if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Trigger(ctx, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := map[string]time.Duration{
+		"test_stale":   2 * time.Hour,
+		"test_expired": 48 * time.Hour,
+	}
+	for hostname, age := range stale {
+		data, err := json.Marshal(publishedStatistics{
+			Jobs:        map[string]Statistics{"test": {TotalTask: 1, PassedTask: 1}},
+			PublishedAt: time.Now().Add(-age),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Set(ctx, c.statisticsKey(hostname), string(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Set(ctx, c.instancesKey(), `["test_stale","test_expired","test_missing"]`); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.PublishStatistics(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.instances(ctx); err != nil || !reflect.DeepEqual(got, []string{"test_stale", "test_hostname"}) {
+		t.Fatalf("instances() = %v, %v", got, err)
+	}
+	if _, ok, _ := store.Get(ctx, c.statisticsKey("test_expired")); ok {
+		t.Fatal("statistics of test_expired are not deleted")
+	}
+
+	// stale after 3 publish intervals
+	c.publishInterval = 20 * time.Minute
+	got, err := c.ClusterStatistics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Instances) != 1 || got.Total != c.Statistics() {
+		t.Fatalf("ClusterStatistics() = %+v", got)
+	}
+	// never stale without periodical publishing
+	c.publishInterval = 0
+	got, err = c.ClusterStatistics(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := c.Statistics().Add(Statistics{TotalTask: 1, PassedTask: 1}); len(got.Instances) != 2 || got.Total != want {
+		t.Fatalf("ClusterStatistics() = %+v", got)
+	}
+}
+
+func TestCron_ClusterStatistics_NoStore(t *testing.T) {
+	c := NewCron()
+	if err := c.PublishStatistics(context.Background()); !errors.Is(err, ErrNoStore) {
+		t.Fatalf("PublishStatistics() error = %v, want %v", err, ErrNoStore)
+	}
+	if _, err := c.ClusterStatistics(context.Background()); !errors.Is(err, ErrNoStore) {
+		t.Fatalf("ClusterStatistics() error = %v, want %v", err, ErrNoStore)
+	}
+}
+
+func TestWithStatisticsPublishing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := newMapStore(ctrl)
+
+	c := NewCron(WithKey("test_cron"), WithHostname("test_hostname"), WithStore(store), WithStatisticsPublishing(10*time.Millisecond))
+	if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Trigger(context.Background(), "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Start()
+	for i := 0; ; i++ {
+		got, err := c.ClusterStatistics(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Total == c.Statistics() {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("ClusterStatistics() = %+v", got)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	<-c.Stop().Done()
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -17,28 +19,68 @@ type CronMeta interface {
 	Key() string
 	// Hostname returns current hostname.
 	Hostname() string
-	// Statistics returns statistics info of the cron's all jobs.
+	// Statistics returns a snapshot of statistics info of the cron's all jobs.
 	Statistics() Statistics
+	// RecentStatistics returns statistics info of the cron's all jobs in the recent window,
+	// like 5 minutes or an hour, with minute granularity up to an hour and hour granularity up to 24 hours.
+	RecentStatistics(window time.Duration) Statistics
 	// Jobs returns the cron's all jobs as JobMeta.
 	Jobs() []JobMeta
+	// RunningTasks returns the number of tasks in progress on the cron instance.
+	RunningTasks() int
+	// State returns the lifecycle state of the cron instance.
+	State() State
 }
 
 // Cron keeps track of any number of jobs, invoking the associated func as specified.
 type Cron struct {
-	key           string
-	hostname      string
-	cron          *cron.Cron
-	atomic        Atomic
-	jobs          []*innerJob
-	location      *time.Location
-	context       context.Context
-	contextCancel context.CancelFunc
+	key             string
+	hostname        string
+	cron            *cron.Cron
+	atomic          Atomic
+	store           Store
+	taskStore       TaskStore
+	taskRetention   time.Duration
+	publishInterval time.Duration
+	persister       StatisticsPersister
+	persistInterval time.Duration
+	limiter         Group
+	admission       []AdmissionCheck
+	tracker         taskTracker
+	jobs            []*innerJob
+	jobsMu          sync.RWMutex
+	location        *time.Location
+	context         context.Context
+	runContext      context.Context
+	runCancel       context.CancelFunc
+	interrupted     int64
+	maintenance     int32
+	state           State
+	stateHooks      []StateHook
+	taskHooks       []TaskHook
+	instrument      Instrument
+	mu              sync.Mutex
 }
 
+// specParser parses specs with the seconds field, like "* * * * * *".
+var specParser = cron.NewParser(
+	cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+var (
+	// ErrInterrupted is the cause of the context returned by Stop when some tasks have been interrupted.
+	ErrInterrupted = errors.New("tasks interrupted")
+	// ErrJobNotFound means there is no job with the key in the cron.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrNoStore means the operation requires a Store, which could be specified by WithStore.
+	ErrNoStore = errors.New("no store")
+)
+
 // NewCron returns a cron with specified options.
 func NewCron(options ...CronOption) *Cron {
 	ret := &Cron{
-		location: time.Local,
+		location:   time.Local,
+		instrument: nopInstrument{},
 	}
 	ret.hostname, _ = os.Hostname()
 	for _, option := range options {
@@ -46,7 +88,7 @@ func NewCron(options ...CronOption) *Cron {
 	}
 
 	ret.cron = cron.New(
-		cron.WithSeconds(),
+		cron.WithParser(specParser),
 		cron.WithLogger(cron.DiscardLogger),
 		cron.WithLocation(ret.location),
 	)
@@ -72,12 +114,6 @@ func (c *Cron) addJob(job Job) error {
 		return errors.New("empty key")
 	}
 
-	for _, j := range c.jobs {
-		if j.key == job.Key() {
-			return errors.New("added already")
-		}
-	}
-
 	j := &innerJob{
 		cron:        c,
 		entryGetter: c.cron,
@@ -92,6 +128,19 @@ func (c *Cron) addJob(job Job) error {
 	if j.retryTimes < 1 {
 		j.retryTimes = 1
 	}
+	if c.persister != nil {
+		// load before locking, so a slow persister will not block other callers
+		c.loadStatistics(j)
+	}
+
+	c.jobsMu.Lock()
+	defer c.jobsMu.Unlock()
+
+	for _, v := range c.jobs {
+		if v.key == j.key {
+			return errors.New("added already")
+		}
+	}
 
 	entryID, err := c.cron.AddJob(j.Spec(), j)
 	if err != nil {
@@ -102,35 +151,366 @@ func (c *Cron) addJob(job Job) error {
 	return nil
 }
 
+// RemoveJob removes the job from the cron, running tasks of it will not be interrupted.
+func (c *Cron) RemoveJob(key string) error {
+	c.jobsMu.Lock()
+	defer c.jobsMu.Unlock()
+
+	for i, j := range c.jobs {
+		if j.key == key {
+			c.cron.Remove(j.entryID)
+			c.jobs = append(c.jobs[:i:i], c.jobs[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrJobNotFound, key)
+}
+
+// UpdateJobSpec changes the spec of the job,
+// its statistics, options and group are kept.
+func (c *Cron) UpdateJobSpec(key, spec string) error {
+	schedule, err := specParser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("invalid spec %q: %w", spec, err)
+	}
+
+	c.jobsMu.Lock()
+	defer c.jobsMu.Unlock()
+
+	for _, j := range c.jobs {
+		if j.key == key {
+			j.mu.Lock()
+			old := j.entryID
+			j.entryID = c.cron.Schedule(schedule, j)
+			j.spec = spec
+			j.mu.Unlock()
+
+			c.cron.Remove(old)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrJobNotFound, key)
+}
+
+// Trigger runs the job immediately, and returns the task once it has finished.
+// The task goes through the same process as scheduled ones, except that it is not affected by pausing.
+// Its key is distinct from the keys of scheduled tasks, and only one instance will run it
+// if the job is triggered on multiple instances in the same second.
+// The task will be interrupted if the context is done or the cron is stopped.
+func (c *Cron) Trigger(ctx context.Context, key string) (Task, error) {
+	j := c.job(key)
+	if j == nil {
+		return Task{}, fmt.Errorf("%w: %s", ErrJobNotFound, key)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(c.runningContext(), cancel)
+	defer stop()
+
+	planAt := time.Now().Truncate(time.Second)
+	return j.exec(ctx, Task{
+		Key:    fmt.Sprintf("dcron:%s.%s@%d/manual", c.key, j.key, planAt.Unix()),
+		Cron:   c,
+		Job:    j,
+		PlanAt: planAt,
+		Manual: true,
+	}), nil
+}
+
+// PauseJob pauses the job, its tasks will be skipped until it is resumed.
+// If the cron has a Store, the job will be paused on all instances of the cron.
+func (c *Cron) PauseJob(key string) error {
+	j := c.job(key)
+	if j == nil {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, key)
+	}
+	if c.store != nil {
+		return c.store.Set(context.Background(), j.pausedKey(), c.hostname)
+	}
+	j.setPaused(true)
+	return nil
+}
+
+// ResumeJob resumes the paused job.
+// If the cron has a Store, the job will be resumed on all instances of the cron.
+func (c *Cron) ResumeJob(key string) error {
+	j := c.job(key)
+	if j == nil {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, key)
+	}
+	if c.store != nil {
+		return c.store.Delete(context.Background(), j.pausedKey())
+	}
+	j.setPaused(false)
+	return nil
+}
+
+// SetMaintenance enables or disables maintenance mode, tasks of all jobs will be skipped in maintenance mode.
+// If the cron has a Store, it will take effect on all instances of the cron.
+func (c *Cron) SetMaintenance(enabled bool) error {
+	if c.store != nil {
+		if enabled {
+			return c.store.Set(context.Background(), c.maintenanceKey(), c.hostname)
+		}
+		return c.store.Delete(context.Background(), c.maintenanceKey())
+	}
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&c.maintenance, v)
+	return nil
+}
+
+func (c *Cron) maintenanceKey() string {
+	return fmt.Sprintf("dcron:%s/maintenance", c.key)
+}
+
+// isPaused reports whether the job is paused or the cron is in maintenance mode.
+// Errors of the Store are ignored, so the job keeps running when the Store is unavailable.
+func (c *Cron) isPaused(ctx context.Context, j *innerJob) bool {
+	if c.store == nil {
+		return atomic.LoadInt32(&c.maintenance) == 1 || j.isPaused()
+	}
+	for _, key := range []string{c.maintenanceKey(), j.pausedKey()} {
+		if _, ok, err := c.store.Get(ctx, key); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cron) job(key string) *innerJob {
+	c.jobsMu.RLock()
+	defer c.jobsMu.RUnlock()
+
+	for _, j := range c.jobs {
+		if j.key == key {
+			return j
+		}
+	}
+	return nil
+}
+
+// allJobs returns a copy of the jobs, so it is safe to iterate it without lock.
+func (c *Cron) allJobs() []*innerJob {
+	c.jobsMu.RLock()
+	defer c.jobsMu.RUnlock()
+
+	return append([]*innerJob(nil), c.jobs...)
+}
+
 // Start the cron scheduler in its own goroutine, or no-op if already started.
+// It could be called again after the cron has been stopped,
+// or to resume acquiring tasks after draining.
 func (c *Cron) Start() {
-	if c.context != nil {
-		go func() {
-			<-c.context.Done()
-			c.Stop()
-		}()
-	}
+	c.prepare()
 	c.cron.Start()
 }
 
 // Stop stops the cron scheduler if it is running; otherwise it does nothing.
-// A context is returned so the caller can wait for running jobs to complete.
+// The contexts of running tasks are canceled, and retrying tasks give up waiting.
+// New tasks, including triggered ones, are declined until the cron is started again.
+// A context is returned so the caller can wait for running jobs to complete,
+// its cause will be ErrInterrupted if any task has been interrupted.
 func (c *Cron) Stop() context.Context {
-	if c.contextCancel != nil {
-		c.contextCancel()
+	// read before canceling, tasks could be interrupted as soon as their contexts are canceled
+	interrupted := atomic.LoadInt64(&c.interrupted)
+	// stop scheduling and accepting new tasks first, so no task could begin after its context is canceled
+	stopped := c.cron.Stop()
+	c.tracker.setDraining(true)
+	c.cancelTasks()
+	c.setState(StateStopped, StateCreated, StateRunning, StateDraining)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		<-stopped.Done()
+		_ = c.tracker.wait(context.Background())
+		if c.persister != nil {
+			_ = c.persistStatistics(context.Background())
+		}
+		if atomic.LoadInt64(&c.interrupted) > interrupted {
+			cancel(ErrInterrupted)
+		} else {
+			cancel(nil)
+		}
+	}()
+	return ctx
+}
+
+// Shutdown stops the cron scheduler gracefully.
+// It stops scheduling new tasks, and waits for running tasks to complete until the context is done,
+// then the contexts of the tasks still running are canceled, and it waits for them to return
+// and their AfterFuncs to be called.
+// An error listing the tasks which did not finish in time is returned if any.
+// Note that a task ignoring its context will block Shutdown.
+func (c *Cron) Shutdown(ctx context.Context) error {
+	c.setState(StateDraining, StateRunning)
+	c.tracker.setDraining(true)
+	stopped := c.cron.Stop()
+	wait := func(ctx context.Context) error {
+		select {
+		case <-stopped.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return c.tracker.wait(ctx)
+	}
+
+	err := wait(ctx)
+	var unfinished []string
+	if err != nil {
+		unfinished = c.tracker.keys()
 	}
-	return c.cron.Stop()
+	c.cancelTasks()
+	_ = wait(context.Background())
+	if c.persister != nil {
+		_ = c.persistStatistics(context.Background())
+	}
+	c.setState(StateStopped, StateRunning, StateDraining)
+
+	if len(unfinished) != 0 {
+		return fmt.Errorf("%d tasks did not finish in time: %s: %w", len(unfinished), strings.Join(unfinished, ", "), err)
+	}
+	return nil
+}
+
+// cancelTasks cancels the contexts of tasks.
+// The canceled context is kept until the cron is started again, so tasks created later are canceled too.
+func (c *Cron) cancelTasks() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.runCancel != nil {
+		c.runCancel()
+	}
+}
+
+// DrainSummary describes the result of draining a cron.
+type DrainSummary struct {
+	Running  int           // Number of tasks in progress when draining started
+	Finished int           // Number of those tasks finished before returning
+	Declined int64         // Number of tasks declined while draining
+	Elapsed  time.Duration // How long the draining took
+}
+
+// Drain stops the cron instance acquiring new tasks, and waits for the tasks in progress to finish,
+// including the ones sleeping before retrying.
+// Unlike Stop, the scheduler keeps running and tasks are declined until the cron is stopped,
+// so it is ready to exit once Drain returns without error.
+// An error is returned if the context is done before all tasks finished.
+func (c *Cron) Drain(ctx context.Context) (DrainSummary, error) {
+	beginAt := time.Now()
+	declined := c.declinedTasks()
+
+	c.setState(StateDraining, StateRunning)
+	running := c.tracker.setDraining(true)
+	err := c.tracker.wait(ctx)
+
+	return DrainSummary{
+		Running:  running,
+		Finished: running - c.tracker.count(),
+		Declined: c.declinedTasks() - declined,
+		Elapsed:  time.Since(beginAt),
+	}, err
+}
+
+func (c *Cron) declinedTasks() int64 {
+	var ret int64
+	for _, j := range c.allJobs() {
+		ret += j.statistics.snapshot().DeclinedTask
+	}
+	return ret
 }
 
 // Run the cron scheduler, or no-op if already running.
 func (c *Cron) Run() {
+	c.prepare()
+	c.cron.Run()
+}
+
+// prepare creates the context of running tasks if it has not been created or has been canceled,
+// and resumes acquiring tasks.
+func (c *Cron) prepare() {
+	var started context.Context
+	c.mu.Lock()
+	if c.runContext == nil || c.runContext.Err() != nil {
+		parent := c.context
+		if parent == nil {
+			parent = context.Background()
+		}
+		c.runContext, c.runCancel = context.WithCancel(parent)
+
+		if parent := c.context; parent != nil {
+			runContext := c.runContext
+			go func() {
+				<-runContext.Done()
+				if parent.Err() != nil {
+					c.Stop()
+				}
+			}()
+		}
+		started = c.runContext
+	}
+	c.mu.Unlock()
+
+	c.tracker.setDraining(false)
+	c.setState(StateRunning, StateCreated, StateDraining, StateStopped)
+
+	if started != nil {
+		if c.taskStore != nil && c.taskRetention > 0 {
+			go c.pruneTasks(started)
+		}
+		if c.store != nil && c.publishInterval > 0 {
+			go c.publishStatistics(started)
+		}
+		if c.persister != nil && c.persistInterval > 0 {
+			go c.flushStatistics(started)
+		}
+		for _, j := range c.allJobs() {
+			if j.misfire != nil && c.store != nil {
+				go j.recoverMisfires(started, time.Now())
+			}
+		}
+	}
+}
+
+// setState changes the state to the target if the current state is one of the given ones,
+// and calls the state hooks if it has been changed.
+func (c *Cron) setState(to State, from ...State) {
+	c.mu.Lock()
+	current := c.state
+	changed := false
+	for _, v := range from {
+		if v == current {
+			c.state = to
+			changed = true
+			break
+		}
+	}
+	hooks := c.stateHooks
+	c.mu.Unlock()
+
+	if changed {
+		for _, hook := range hooks {
+			hook(c, current, to)
+		}
+	}
+}
+
+// runningContext returns the parent context of tasks.
+func (c *Cron) runningContext() context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.runContext != nil {
+		return c.runContext
+	}
 	if c.context != nil {
-		go func() {
-			<-c.context.Done()
-			c.Stop()
-		}()
+		return c.context
 	}
-	c.cron.Run()
+	return context.Background()
 }
 
 // Key implements CronMeta.Key
@@ -146,8 +526,35 @@ func (c *Cron) Hostname() string {
 // Statistics implements CronMeta.Statistics
 func (c *Cron) Statistics() Statistics {
 	ret := Statistics{}
-	for _, j := range c.jobs {
-		ret = ret.Add(j.statistics)
+	for _, j := range c.allJobs() {
+		ret = ret.Add(j.statistics.snapshot())
+	}
+	return ret
+}
+
+// RecentStatistics implements CronMeta.RecentStatistics
+func (c *Cron) RecentStatistics(window time.Duration) Statistics {
+	ret := Statistics{}
+	for _, j := range c.allJobs() {
+		ret = ret.Add(j.statistics.recent(window))
+	}
+	return ret
+}
+
+// ResetStatistics resets statistics of the cron's all jobs to zero.
+func (c *Cron) ResetStatistics() {
+	for _, j := range c.allJobs() {
+		j.statistics.reset()
+	}
+}
+
+// StatisticsDelta returns how statistics of the cron's all jobs have changed
+// since the last call of StatisticsDelta or ResetStatistics, it is useful for periodic reporting.
+// Note that statistics of removed jobs are not included.
+func (c *Cron) StatisticsDelta() Statistics {
+	ret := Statistics{}
+	for _, j := range c.allJobs() {
+		ret = ret.Add(j.statistics.delta())
 	}
 	return ret
 }
@@ -155,8 +562,30 @@ func (c *Cron) Statistics() Statistics {
 // Jobs implements CronMeta.Jobs
 func (c *Cron) Jobs() []JobMeta {
 	var ret []JobMeta
-	for _, j := range c.jobs {
+	for _, j := range c.allJobs() {
 		ret = append(ret, j)
 	}
 	return ret
 }
+
+// RunningTasks implements CronMeta.RunningTasks
+func (c *Cron) RunningTasks() int {
+	return c.tracker.count()
+}
+
+func (c *Cron) admit(task Task) bool {
+	for _, check := range c.admission {
+		if !check(task) {
+			return false
+		}
+	}
+	return true
+}
+
+// State implements CronMeta.State
+func (c *Cron) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.state
+}
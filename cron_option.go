@@ -5,6 +5,26 @@ import (
 	"time"
 )
 
+// OverloadPolicy indicates what to do with a task which can not get a slot to run.
+type OverloadPolicy int
+
+const (
+	// OverloadSkip gives up the task immediately without acquiring it, so another instance could take it.
+	OverloadSkip OverloadPolicy = iota
+	// OverloadWait waits for a free slot until the deadline of the task.
+	OverloadWait
+)
+
+// AdmissionCheck reports whether the cron instance is healthy enough to acquire the task.
+type AdmissionCheck func(task Task) bool
+
+// MaxRunningTasks returns an AdmissionCheck which fails when n or more tasks are running on the cron instance.
+func MaxRunningTasks(n int) AdmissionCheck {
+	return func(task Task) bool {
+		return task.Cron.RunningTasks() < n
+	}
+}
+
 // CronOption represents a modification to the default behavior of a Cron.
 type CronOption func(c *Cron)
 
@@ -29,6 +49,49 @@ func WithAtomic(atomic Atomic) CronOption {
 	}
 }
 
+// WithStore uses the provided Store to share states between instances of the cron.
+func WithStore(store Store) CronOption {
+	return func(c *Cron) {
+		c.store = store
+	}
+}
+
+// WithTaskStore uses the provided TaskStore to record every task processed by the cron instance,
+// errors of saving records are ignored.
+func WithTaskStore(store TaskStore) CronOption {
+	return func(c *Cron) {
+		c.taskStore = store
+	}
+}
+
+// WithStatisticsPublishing makes the cron publish statistics to the Store at the interval while it is running,
+// so Cron.ClusterStatistics could merge statistics of all instances,
+// and skip the ones not published in the last 3 intervals.
+// It requires a Store specified by WithStore.
+func WithStatisticsPublishing(interval time.Duration) CronOption {
+	return func(c *Cron) {
+		c.publishInterval = interval
+	}
+}
+
+// WithStatisticsPersister specifies how to persist statistics of jobs, so they are kept across restarts.
+// Statistics are loaded when jobs are added, and saved at the interval while the cron is running,
+// as well as when the cron is stopped. They are only saved on stopping if interval is not positive.
+func WithStatisticsPersister(persister StatisticsPersister, interval time.Duration) CronOption {
+	return func(c *Cron) {
+		c.persister = persister
+		c.persistInterval = interval
+	}
+}
+
+// WithTaskRetention specifies how long records of tasks should be kept in the TaskStore,
+// expired records are pruned periodically while the cron is running.
+func WithTaskRetention(retention time.Duration) CronOption {
+	return func(c *Cron) {
+		c.taskRetention = retention
+	}
+}
+
 // WithLocation overrides the timezone of the cron instance.
 func WithLocation(loc *time.Location) CronOption {
 	return func(c *Cron) {
@@ -41,6 +104,56 @@ func WithLocation(loc *time.Location) CronOption {
 // and when the context is done, the cron will be stopped.
 func WithContext(ctx context.Context) CronOption {
 	return func(c *Cron) {
-		c.context, c.contextCancel = context.WithCancel(ctx)
+		c.context = ctx
+	}
+}
+
+// WithMaxConcurrentTasks limits how many tasks the cron instance could run simultaneously,
+// the policy decides what to do with a task which can not get a slot,
+// and the task is declined if it still can not get one.
+// It is unlimited if n is not positive.
+func WithMaxConcurrentTasks(n int, policy OverloadPolicy) CronOption {
+	return func(c *Cron) {
+		var options []ConcurrencyGroupOption
+		if policy == OverloadWait {
+			options = append(options, WithQueue(0))
+		}
+		c.limiter = NewConcurrencyGroup(n, options...)
+	}
+}
+
+// WithAdmissionCheck specifies checks consulted before acquiring a task,
+// if any of them fails, the task will be declined without acquiring,
+// so healthier instances could take it.
+func WithAdmissionCheck(checks ...AdmissionCheck) CronOption {
+	return func(c *Cron) {
+		c.admission = append(c.admission, checks...)
+	}
+}
+
+// WithTaskHook specifies what to do when a task of any job finishes,
+// it is called after the AfterFunc of the job, and could be specified multiple times.
+func WithTaskHook(hook TaskHook) CronOption {
+	return func(c *Cron) {
+		c.taskHooks = append(c.taskHooks, hook)
+	}
+}
+
+// WithInstrument specifies an Instrument to observe steps of processing tasks,
+// nil means no Instrument.
+func WithInstrument(instrument Instrument) CronOption {
+	return func(c *Cron) {
+		if instrument == nil {
+			instrument = nopInstrument{}
+		}
+		c.instrument = instrument
+	}
+}
+
+// WithStateHook specifies what to do when the state of the cron changes,
+// it could be used multiple times to add more hooks.
+func WithStateHook(hook StateHook) CronOption {
+	return func(c *Cron) {
+		c.stateHooks = append(c.stateHooks, hook)
 	}
 }
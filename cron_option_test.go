@@ -174,3 +174,272 @@ func TestWithContext(t *testing.T) {
 		c.Run()
 	})
 }
+
+func TestWithMaxConcurrentTasks(t *testing.T) {
+	type args struct {
+		n      int
+		policy OverloadPolicy
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(t *testing.T, option CronOption)
+	}{
+		{
+			name: "skip",
+			args: args{
+				n:      1,
+				policy: OverloadSkip,
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := NewCron()
+				option(c)
+				ctx := context.Background()
+				now := time.Now()
+				if !c.limiter.inc(ctx, now, 0, func() bool { return true }) {
+					t.Fatal("inc() should pass")
+				}
+				if c.limiter.inc(ctx, now, 0, func() bool { return true }) {
+					t.Fatal("inc() should be skipped")
+				}
+			},
+		},
+		{
+			name: "wait",
+			args: args{
+				n:      1,
+				policy: OverloadWait,
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := NewCron()
+				option(c)
+				ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+				defer cancel()
+				now := time.Now()
+				if !c.limiter.inc(ctx, now, 0, func() bool { return true }) {
+					t.Fatal("inc() should pass")
+				}
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					c.limiter.dec(now)
+				}()
+				if !c.limiter.inc(ctx, now, 0, func() bool { return true }) {
+					t.Fatal("inc() should pass after waiting")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithMaxConcurrentTasks(tt.args.n, tt.args.policy)
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestWithAdmissionCheck(t *testing.T) {
+	type args struct {
+		checks []AdmissionCheck
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(t *testing.T, option CronOption)
+	}{
+		{
+			name: "pass",
+			args: args{
+				checks: []AdmissionCheck{
+					func(task Task) bool { return true },
+					MaxRunningTasks(1),
+				},
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := NewCron()
+				option(c)
+				if !c.admit(Task{Cron: c}) {
+					t.Fatal("admit() should pass")
+				}
+			},
+		},
+		{
+			name: "check failed",
+			args: args{
+				checks: []AdmissionCheck{
+					func(task Task) bool { return false },
+				},
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := NewCron()
+				option(c)
+				if c.admit(Task{Cron: c}) {
+					t.Fatal("admit() should fail")
+				}
+			},
+		},
+		{
+			name: "too many running tasks",
+			args: args{
+				checks: []AdmissionCheck{
+					MaxRunningTasks(1),
+				},
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := NewCron()
+				option(c)
+				c.tracker.begin("test")
+				if c.admit(Task{Cron: c}) {
+					t.Fatal("admit() should fail")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithAdmissionCheck(tt.args.checks...)
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestWithStore(t *testing.T) {
+	type args struct {
+		store Store
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(t *testing.T, option CronOption)
+	}{
+		{
+			name: "regular",
+			args: args{
+				store: mock_dcron.NewMockStore(nil),
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := NewCron()
+				option(c)
+				if c.store == nil {
+					t.Fatal(c.store)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithStore(tt.args.store)
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestWithTaskHook(t *testing.T) {
+	called := 0
+
+	type args struct {
+		hooks []TaskHook
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(t *testing.T, c *Cron)
+	}{
+		{
+			name: "regular",
+			args: args{
+				hooks: []TaskHook{
+					func(task Task) {},
+					func(task Task) {},
+				},
+			},
+			check: func(t *testing.T, c *Cron) {
+				if len(c.taskHooks) != 2 {
+					t.Fatal(len(c.taskHooks))
+				}
+			},
+		},
+		{
+			name: "called when task finishes",
+			args: args{
+				hooks: []TaskHook{
+					func(task Task) {
+						if task.Job.Key() != "test" || task.TriedTimes != 1 {
+							t.Fatal(task)
+						}
+						called++
+					},
+				},
+			},
+			check: func(t *testing.T, c *Cron) {
+				if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+					return nil
+				})); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := c.Trigger(context.Background(), "test"); err != nil {
+					t.Fatal(err)
+				}
+				if called != 1 {
+					t.Fatal(called)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCron()
+			for _, hook := range tt.args.hooks {
+				WithTaskHook(hook)(c)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestWithInstrument(t *testing.T) {
+	type args struct {
+		instrument Instrument
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(t *testing.T, option CronOption)
+	}{
+		{
+			name: "regular",
+			args: args{
+				instrument: nopInstrument{},
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := &Cron{}
+				option(c)
+				if c.instrument != (nopInstrument{}) {
+					t.Fatal(c.instrument)
+				}
+			},
+		},
+		{
+			name: "nil",
+			args: args{
+				instrument: nil,
+			},
+			check: func(t *testing.T, option CronOption) {
+				c := NewCron(option)
+				if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+					return nil
+				})); err != nil {
+					t.Fatal(err)
+				}
+				if task, err := c.Trigger(context.Background(), "test"); err != nil || task.Return != nil {
+					t.Fatal(task, err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithInstrument(tt.args.instrument)
+			tt.check(t, got)
+		})
+	}
+}
@@ -2,7 +2,13 @@ package dcron
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -335,3 +341,567 @@ func Test_JobWithGroup(t *testing.T) {
 		t.Logf("job %v statistics: %+v", j.Key(), j.Statistics())
 	}
 }
+
+func TestCron_Drain(t *testing.T) {
+	c := NewCron(WithKey("test_cron"))
+
+	started := make(chan struct{}, 1)
+	if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		time.Sleep(1500 * time.Millisecond)
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	c.Start()
+	defer c.Stop()
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	summary, err := c.Drain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Running < 1 || summary.Finished != summary.Running {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if got := c.RunningTasks(); got != 0 {
+		t.Fatalf("RunningTasks() = %v, want 0", got)
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if got := c.declinedTasks(); got < 1 {
+		t.Fatalf("DeclinedTask = %v, want at least 1", got)
+	}
+}
+
+func TestCron_Stop(t *testing.T) {
+	c := NewCron(WithKey("test_cron"))
+
+	failed := make(chan struct{}, 1)
+	tasks := make(chan Task, 10)
+	if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+		select {
+		case failed <- struct{}{}:
+		default:
+		}
+		return errors.New("should retry")
+	}, WithRetryTimes(3), WithRetryInterval(func(triedTimes int) time.Duration {
+		return 5 * time.Second
+	}), WithAfterFunc(func(task Task) {
+		tasks <- task
+	}))); err != nil {
+		t.Fatal(err)
+	}
+	c.Start()
+
+	go func() {
+		_, _ = c.Trigger(context.Background(), "test")
+	}()
+	<-failed
+	beginAt := time.Now()
+	ctx := c.Stop()
+	<-ctx.Done()
+	if time.Since(beginAt) > time.Second {
+		t.Fatalf("Stop() took %v", time.Since(beginAt))
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrInterrupted) {
+		t.Fatalf("Cause() = %v, want %v", cause, ErrInterrupted)
+	}
+	task := <-tasks
+	if !task.Interrupted || task.TriedTimes != 1 {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+}
+
+func TestCron_Stop_SlowStateHook(t *testing.T) {
+	c := NewCron(WithKey("test_cron"), WithStateHook(func(c CronMeta, from, to State) {
+		if to == StateStopped {
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+
+	running := make(chan struct{})
+	if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+		close(running)
+		<-ctx.Done()
+		return ctx.Err()
+	})); err != nil {
+		t.Fatal(err)
+	}
+	c.Start()
+
+	tasks := make(chan Task, 1)
+	go func() {
+		task, _ := c.Trigger(context.Background(), "test")
+		tasks <- task
+	}()
+	<-running
+
+	ctx := c.Stop()
+	<-ctx.Done()
+	if task := <-tasks; !task.Interrupted {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if cause := context.Cause(ctx); !errors.Is(cause, ErrInterrupted) {
+		t.Fatalf("Cause() = %v, want %v", cause, ErrInterrupted)
+	}
+}
+
+func TestCron_Stop_NoNewTasks(t *testing.T) {
+	c := NewCron(WithKey("test_cron"), WithStateHook(func(c CronMeta, from, to State) {
+		if to == StateStopped {
+			time.Sleep(1500 * time.Millisecond)
+		}
+	}))
+
+	var mu sync.Mutex
+	var tasks []Task
+	if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(3 * time.Second):
+			return nil
+		}
+	}, WithAfterFunc(func(task Task) {
+		mu.Lock()
+		defer mu.Unlock()
+		tasks = append(tasks, task)
+	}))); err != nil {
+		t.Fatal(err)
+	}
+	c.Start()
+	<-c.Stop().Done()
+
+	if task, err := c.Trigger(context.Background(), "test"); err != nil || !task.Declined {
+		t.Fatalf("Trigger() = %+v, %v", task, err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, task := range tasks {
+		// tasks began after stopping would not be canceled
+		if !task.Declined && !task.Interrupted {
+			t.Fatalf("unexpected task: %+v", task)
+		}
+	}
+}
+
+func TestCron_Shutdown(t *testing.T) {
+	t.Run("finished in time", func(t *testing.T) {
+		c := NewCron(WithKey("test_cron"))
+		started := make(chan struct{}, 1)
+		if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			time.Sleep(500 * time.Millisecond)
+			return nil
+		})); err != nil {
+			t.Fatal(err)
+		}
+		c.Start()
+
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := c.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		c := NewCron(WithKey("test_cron"))
+		started := make(chan struct{}, 1)
+		var after []Task
+		if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithAfterFunc(func(task Task) {
+			after = append(after, task)
+		}))); err != nil {
+			t.Fatal(err)
+		}
+		c.Start()
+
+		go func() {
+			_, _ = c.Trigger(context.Background(), "test")
+		}()
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		err := c.Shutdown(ctx)
+		if err == nil || !strings.Contains(err.Error(), "dcron:test_cron.test@") {
+			t.Fatalf("Shutdown() error = %v", err)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Shutdown() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if len(after) != 1 || !after[0].Interrupted {
+			t.Fatalf("unexpected tasks: %+v", after)
+		}
+	})
+}
+
+func TestCron_State(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var changes []string
+	c := NewCron(WithKey("test_cron"), WithContext(ctx), WithStateHook(func(c CronMeta, from, to State) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, from.String()+"->"+to.String())
+	}))
+
+	var runs int64
+	if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+		atomic.AddInt64(&runs, 1)
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.State(); got != StateCreated {
+		t.Fatalf("State() = %v, want %v", got, StateCreated)
+	}
+
+	c.Start()
+	time.Sleep(1500 * time.Millisecond)
+	<-c.Stop().Done()
+	if got := c.State(); got != StateStopped {
+		t.Fatalf("State() = %v, want %v", got, StateStopped)
+	}
+
+	before := atomic.LoadInt64(&runs)
+	c.Start()
+	if _, err := c.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.Start()
+	time.Sleep(1500 * time.Millisecond)
+	if atomic.LoadInt64(&runs) == before {
+		t.Fatal("should run after restarting")
+	}
+
+	cancel()
+	time.Sleep(100 * time.Millisecond)
+	if got := c.State(); got != StateStopped {
+		t.Fatalf("State() = %v, want %v", got, StateStopped)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{
+		"created->running",
+		"running->stopped",
+		"stopped->running",
+		"running->draining",
+		"draining->running",
+		"running->stopped",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+}
+
+func TestCron_RemoveJob(t *testing.T) {
+	c := NewCron(WithKey("test_cron"))
+	if err := c.AddJobs(
+		NewJob("test1", "* * * * * *", nil),
+		NewJob("test2", "* * * * * *", nil),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.RemoveJob("test1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RemoveJob("test1"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("RemoveJob() error = %v, want %v", err, ErrJobNotFound)
+	}
+	if got := c.Jobs(); len(got) != 1 || got[0].Key() != "test2" {
+		t.Fatalf("Jobs() = %v", got)
+	}
+	if got := c.cron.Entries(); len(got) != 1 {
+		t.Fatalf("Entries() = %v", got)
+	}
+	if err := c.AddJobs(NewJob("test1", "* * * * * *", nil)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCron_PauseJob(t *testing.T) {
+	c := NewCron(WithKey("test_cron"))
+	if err := c.AddJobs(NewJob("test", "* * * * * *", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.PauseJob("test"); err != nil {
+		t.Fatal(err)
+	}
+	if !c.job("test").isPaused() {
+		t.Fatal("should be paused")
+	}
+	if err := c.ResumeJob("test"); err != nil {
+		t.Fatal(err)
+	}
+	if c.job("test").isPaused() {
+		t.Fatal("should be resumed")
+	}
+
+	if err := c.PauseJob("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("PauseJob() error = %v, want %v", err, ErrJobNotFound)
+	}
+	if err := c.ResumeJob("unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("ResumeJob() error = %v, want %v", err, ErrJobNotFound)
+	}
+}
+
+func TestCron_PauseJob_WithStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mock_dcron.NewMockStore(ctrl)
+
+	c := NewCron(WithKey("test_cron"), WithHostname("test_hostname"), WithStore(store))
+	if err := c.AddJobs(NewJob("test", "* * * * * *", nil)); err != nil {
+		t.Fatal(err)
+	}
+	j := c.job("test")
+	ctx := context.Background()
+
+	store.EXPECT().Set(gomock.Any(), "dcron:test_cron.test/paused", "test_hostname").Return(nil)
+	if err := c.PauseJob("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	store.EXPECT().Get(gomock.Any(), "dcron:test_cron/maintenance").Return("", false, nil)
+	store.EXPECT().Get(gomock.Any(), "dcron:test_cron.test/paused").Return("other_hostname", true, nil)
+	if !c.isPaused(ctx, j) {
+		t.Fatal("should be paused")
+	}
+
+	store.EXPECT().Delete(gomock.Any(), "dcron:test_cron.test/paused").Return(nil)
+	if err := c.ResumeJob("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", false, errors.New("unavailable")).Times(2)
+	if c.isPaused(ctx, j) {
+		t.Fatal("should not be paused when store is unavailable")
+	}
+}
+
+func TestCron_SetMaintenance(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		c := NewCron(WithKey("test_cron"))
+		if err := c.AddJobs(NewJob("test", "* * * * * *", nil)); err != nil {
+			t.Fatal(err)
+		}
+		j := c.job("test")
+		ctx := context.Background()
+
+		if err := c.SetMaintenance(true); err != nil {
+			t.Fatal(err)
+		}
+		if !c.isPaused(ctx, j) {
+			t.Fatal("should be paused")
+		}
+		if err := c.SetMaintenance(false); err != nil {
+			t.Fatal(err)
+		}
+		if c.isPaused(ctx, j) {
+			t.Fatal("should not be paused")
+		}
+	})
+
+	t.Run("with store", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		store := mock_dcron.NewMockStore(ctrl)
+
+		c := NewCron(WithKey("test_cron"), WithHostname("test_hostname"), WithStore(store))
+		if err := c.AddJobs(NewJob("test", "* * * * * *", nil)); err != nil {
+			t.Fatal(err)
+		}
+		j := c.job("test")
+
+		store.EXPECT().Set(gomock.Any(), "dcron:test_cron/maintenance", "test_hostname").Return(nil)
+		if err := c.SetMaintenance(true); err != nil {
+			t.Fatal(err)
+		}
+		store.EXPECT().Get(gomock.Any(), "dcron:test_cron/maintenance").Return("test_hostname", true, nil)
+		if !c.isPaused(context.Background(), j) {
+			t.Fatal("should be paused")
+		}
+		store.EXPECT().Delete(gomock.Any(), "dcron:test_cron/maintenance").Return(nil)
+		if err := c.SetMaintenance(false); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestCron_UpdateJobSpec(t *testing.T) {
+	c := NewCron(WithKey("test_cron"))
+	var runs int64
+	if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+		atomic.AddInt64(&runs, 1)
+		return nil
+	}, WithRetryTimes(3))); err != nil {
+		t.Fatal(err)
+	}
+	j := c.job("test")
+	j.statistics.add(Statistics{TotalTask: 5})
+
+	if err := c.UpdateJobSpec("test", "* * * * *"); err == nil || !strings.Contains(err.Error(), "invalid spec") {
+		t.Fatalf("UpdateJobSpec() error = %v", err)
+	}
+	if err := c.UpdateJobSpec("unknown", "* * * * * *"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("UpdateJobSpec() error = %v, want %v", err, ErrJobNotFound)
+	}
+	if err := c.UpdateJobSpec("test", "* * * * * *"); err != nil {
+		t.Fatal(err)
+	}
+	if got := j.Spec(); got != "* * * * * *" {
+		t.Fatalf("Spec() = %v", got)
+	}
+	if got := c.cron.Entries(); len(got) != 1 || got[0].ID != j.entryID {
+		t.Fatalf("Entries() = %v", got)
+	}
+	if j.retryTimes != 3 {
+		t.Fatalf("retryTimes = %v", j.retryTimes)
+	}
+
+	c.Start()
+	time.Sleep(1500 * time.Millisecond)
+	<-c.Stop().Done()
+	if atomic.LoadInt64(&runs) == 0 {
+		t.Fatal("should run with the new spec")
+	}
+	if got := c.Statistics().TotalTask; got <= 5 {
+		t.Fatalf("TotalTask = %v, should be kept", got)
+	}
+}
+
+func TestCron_Trigger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	atomic := mock_dcron.NewMockAtomic(ctrl)
+
+	c := NewCron(WithKey("test_cron"), WithAtomic(atomic))
+	var triggered []string
+	atomic.EXPECT().
+		SetIfNotExists(gomock.Any(), gomock.Any(), c.Hostname()).
+		DoAndReturn(func(ctx context.Context, key, value string) bool {
+			for _, v := range triggered {
+				if v == key {
+					return false
+				}
+			}
+			triggered = append(triggered, key)
+			return true
+		}).
+		Times(2)
+
+	var after []Task
+	if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+		if task, ok := TaskFromContext(ctx); !ok || !task.Manual {
+			t.Fatalf("unexpected task: %+v", task)
+		}
+		return nil
+	}, WithAfterFunc(func(task Task) {
+		after = append(after, task)
+	}))); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PauseJob("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Trigger(context.Background(), "unknown"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("Trigger() error = %v, want %v", err, ErrJobNotFound)
+	}
+
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	task, err := c.Trigger(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.Manual || task.Skipped || task.Missed || task.Return != nil || task.TriedTimes != 1 {
+		t.Fatalf("unexpected task: %+v", task)
+	}
+	if want := fmt.Sprintf("dcron:test_cron.test@%d/manual", task.PlanAt.Unix()); task.Key != want {
+		t.Fatalf("Key = %v, want %v", task.Key, want)
+	}
+
+	task, err = c.Trigger(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !task.Missed {
+		t.Fatalf("should be missed in the same second: %+v", task)
+	}
+	if len(after) != 2 {
+		t.Fatalf("AfterFunc called %d times, want 2", len(after))
+	}
+}
+
+func TestCron_ResetStatistics(t *testing.T) {
+	c := NewCron(WithKey("test_cron"))
+	if err := c.AddJobs(
+		NewJob("test1", "0 0 0 1 1 *", func(ctx context.Context) error {
+			return nil
+		}),
+		NewJob("test2", "0 0 0 1 1 *", func(ctx context.Context) error {
+			return errors.New("failed")
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"test1", "test2"} {
+		if _, err := c.Trigger(context.Background(), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := Statistics{TotalTask: 2, PassedTask: 1, FailedTask: 1, TotalRun: 2, PassedRun: 1, FailedRun: 1}
+	if got := c.RecentStatistics(5 * time.Minute); got != want {
+		t.Fatalf("RecentStatistics() = %+v, want %+v", got, want)
+	}
+	if got := c.StatisticsDelta(); got != want {
+		t.Fatalf("StatisticsDelta() = %+v, want %+v", got, want)
+	}
+	if got := c.StatisticsDelta(); got != (Statistics{}) {
+		t.Fatalf("StatisticsDelta() = %+v, want zero", got)
+	}
+	if _, err := c.Trigger(context.Background(), "test1"); err != nil {
+		t.Fatal(err)
+	}
+	want = Statistics{TotalTask: 1, PassedTask: 1, TotalRun: 1, PassedRun: 1}
+	if got := c.StatisticsDelta(); got != want {
+		t.Fatalf("StatisticsDelta() = %+v, want %+v", got, want)
+	}
+
+	c.ResetStatistics()
+	if got := c.Statistics(); got != (Statistics{}) {
+		t.Fatalf("Statistics() = %+v, want zero", got)
+	}
+	for _, j := range c.Jobs() {
+		if got := j.Statistics(); got != (Statistics{}) {
+			t.Fatalf("Statistics() of %v = %+v, want zero", j.Key(), got)
+		}
+	}
+	if got := c.StatisticsDelta(); got != (Statistics{}) {
+		t.Fatalf("StatisticsDelta() = %+v, want zero", got)
+	}
+}
@@ -1,6 +1,8 @@
 package dcron
 
 import (
+	"context"
+	"sort"
 	"sync"
 	"time"
 )
@@ -10,9 +12,11 @@ const (
 )
 
 type Group interface {
-	inc(platAt time.Time, fn func() bool) bool
+	inc(ctx context.Context, platAt time.Time, priority int, fn func() bool) bool
+	dec(platAt time.Time)
 }
 
+// NewGroup returns a group which limits how many tasks of its jobs could run at the same plan time.
 func NewGroup(limit int) Group {
 	return &innerGroup{
 		limit: limit,
@@ -26,7 +30,7 @@ type innerGroup struct {
 	counts []*groupCount
 }
 
-func (g *innerGroup) inc(platAt time.Time, fn func() bool) bool {
+func (g *innerGroup) inc(_ context.Context, platAt time.Time, _ int, fn func() bool) bool {
 	g.Lock()
 	defer g.Unlock()
 	defer g.tidy()
@@ -53,6 +57,10 @@ func (g *innerGroup) inc(platAt time.Time, fn func() bool) bool {
 	return false
 }
 
+func (g *innerGroup) dec(_ time.Time) {
+	// the count of a plan time is never released
+}
+
 func (g *innerGroup) tidy() {
 	if len(g.counts) > 2*minCountKeep {
 		g.counts = g.counts[len(g.counts)-minCountKeep:]
@@ -63,3 +71,188 @@ type groupCount struct {
 	platAt time.Time
 	count  int
 }
+
+// ConcurrencyGroupOption represents a modification to the default behavior of a concurrency group.
+type ConcurrencyGroupOption func(g *concurrencyGroup)
+
+// WithQueue makes tasks wait for a free slot instead of missing immediately when the group is full.
+// A task waits for at most timeout, or until its deadline if timeout is not positive.
+func WithQueue(timeout time.Duration) ConcurrencyGroupOption {
+	return func(g *concurrencyGroup) {
+		g.queue = true
+		g.timeout = timeout
+	}
+}
+
+// NewConcurrencyGroup returns a group which limits how many tasks of its jobs could be running simultaneously,
+// no matter what their plan times are. A slot is released once the task has finished running.
+func NewConcurrencyGroup(limit int, options ...ConcurrencyGroupOption) Group {
+	g := &concurrencyGroup{}
+	if limit > 0 {
+		g.slots = make(chan struct{}, limit)
+	}
+	for _, option := range options {
+		option(g)
+	}
+	return g
+}
+
+type concurrencyGroup struct {
+	slots   chan struct{}
+	queue   bool
+	timeout time.Duration
+}
+
+func (g *concurrencyGroup) inc(ctx context.Context, _ time.Time, _ int, fn func() bool) bool {
+	if g.slots == nil {
+		return fn()
+	}
+
+	if !g.acquire(ctx) {
+		return false
+	}
+	if !fn() {
+		<-g.slots
+		return false
+	}
+	return true
+}
+
+func (g *concurrencyGroup) acquire(ctx context.Context) bool {
+	select {
+	case g.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if !g.queue {
+		return false
+	}
+
+	var timeout <-chan time.Time
+	if g.timeout > 0 {
+		timer := time.NewTimer(g.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case g.slots <- struct{}{}:
+		return true
+	case <-timeout:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (g *concurrencyGroup) dec(_ time.Time) {
+	if g.slots == nil {
+		return
+	}
+	<-g.slots
+}
+
+// NewPriorityGroup returns a group which limits how many tasks of its jobs could run at the same plan time like NewGroup,
+// but collects the tasks of a plan time for the window before admitting them, and admits jobs with higher priority first.
+// Tasks arriving after the window are admitted in the order they arrive.
+func NewPriorityGroup(limit int, window time.Duration) Group {
+	return &priorityGroup{
+		limit:  limit,
+		window: window,
+	}
+}
+
+type priorityGroup struct {
+	sync.Mutex
+
+	limit   int
+	window  time.Duration
+	batches []*priorityBatch
+}
+
+type priorityBatch struct {
+	platAt     time.Time
+	count      int
+	closed     bool
+	candidates []*priorityCandidate
+}
+
+type priorityCandidate struct {
+	priority int
+	fn       func() bool
+	result   chan bool
+}
+
+func (g *priorityGroup) inc(_ context.Context, platAt time.Time, priority int, fn func() bool) bool {
+	g.Lock()
+
+	var b *priorityBatch
+	for i := len(g.batches) - 1; i >= 0; i-- {
+		if v := g.batches[i]; v.platAt.Equal(platAt) {
+			b = v
+			break
+		}
+	}
+	if b == nil {
+		b = &priorityBatch{
+			platAt: platAt,
+		}
+		g.batches = append(g.batches, b)
+		time.AfterFunc(g.window, func() {
+			g.admit(b)
+		})
+	}
+
+	if b.closed {
+		defer g.Unlock()
+		if (b.count < g.limit || g.limit <= 0) && fn() {
+			b.count++
+			return true
+		}
+		return false
+	}
+
+	c := &priorityCandidate{
+		priority: priority,
+		fn:       fn,
+		result:   make(chan bool, 1),
+	}
+	b.candidates = append(b.candidates, c)
+	g.Unlock()
+
+	return <-c.result
+}
+
+func (g *priorityGroup) admit(b *priorityBatch) {
+	g.Lock()
+	defer g.Unlock()
+	defer g.tidy()
+
+	b.closed = true
+	sort.SliceStable(b.candidates, func(i, j int) bool {
+		return b.candidates[i].priority > b.candidates[j].priority
+	})
+	for _, c := range b.candidates {
+		ok := (b.count < g.limit || g.limit <= 0) && c.fn()
+		if ok {
+			b.count++
+		}
+		c.result <- ok
+	}
+	b.candidates = nil
+}
+
+func (g *priorityGroup) dec(_ time.Time) {
+	// the count of a plan time is never released
+}
+
+func (g *priorityGroup) tidy() {
+	if len(g.batches) <= 2*minCountKeep {
+		return
+	}
+	drop := 0
+	for drop < len(g.batches)-minCountKeep && g.batches[drop].closed {
+		drop++
+	}
+	g.batches = g.batches[drop:]
+}
@@ -0,0 +1,179 @@
+package dcron
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_innerGroup(t *testing.T) {
+	g := NewGroup(2)
+	now := time.Now()
+	ctx := context.Background()
+	yes := func() bool { return true }
+
+	for i, want := range []bool{true, true, false} {
+		if got := g.inc(ctx, now, 0, yes); got != want {
+			t.Errorf("inc() #%d = %v, want %v", i, got, want)
+		}
+	}
+	g.dec(now)
+	if g.inc(ctx, now, 0, yes) {
+		t.Errorf("inc() after dec should still be limited by plan time")
+	}
+	if !g.inc(ctx, now.Add(time.Second), 0, yes) {
+		t.Errorf("inc() of another plan time should pass")
+	}
+}
+
+func Test_concurrencyGroup(t *testing.T) {
+	yes := func() bool { return true }
+	no := func() bool { return false }
+
+	tests := []struct {
+		name  string
+		group Group
+		check func(t *testing.T, g Group)
+	}{
+		{
+			name:  "limit across plan times",
+			group: NewConcurrencyGroup(2),
+			check: func(t *testing.T, g Group) {
+				ctx := context.Background()
+				now := time.Now()
+				if !g.inc(ctx, now, 0, yes) || !g.inc(ctx, now.Add(time.Second), 0, yes) {
+					t.Fatal("inc() should pass")
+				}
+				if g.inc(ctx, now.Add(2*time.Second), 0, yes) {
+					t.Fatal("inc() should be limited")
+				}
+				g.dec(now)
+				if !g.inc(ctx, now.Add(2*time.Second), 0, yes) {
+					t.Fatal("inc() should pass after dec")
+				}
+			},
+		},
+		{
+			name:  "release when fn failed",
+			group: NewConcurrencyGroup(1),
+			check: func(t *testing.T, g Group) {
+				ctx := context.Background()
+				now := time.Now()
+				if g.inc(ctx, now, 0, no) {
+					t.Fatal("inc() should fail")
+				}
+				if !g.inc(ctx, now, 0, yes) {
+					t.Fatal("inc() should pass")
+				}
+			},
+		},
+		{
+			name:  "unlimited",
+			group: NewConcurrencyGroup(0),
+			check: func(t *testing.T, g Group) {
+				ctx := context.Background()
+				now := time.Now()
+				for i := 0; i < 100; i++ {
+					if !g.inc(ctx, now, 0, yes) {
+						t.Fatal("inc() should pass")
+					}
+				}
+				g.dec(now)
+			},
+		},
+		{
+			name:  "queue",
+			group: NewConcurrencyGroup(1, WithQueue(time.Second)),
+			check: func(t *testing.T, g Group) {
+				ctx := context.Background()
+				now := time.Now()
+				if !g.inc(ctx, now, 0, yes) {
+					t.Fatal("inc() should pass")
+				}
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					g.dec(now)
+				}()
+				if !g.inc(ctx, now, 0, yes) {
+					t.Fatal("inc() should pass after waiting")
+				}
+			},
+		},
+		{
+			name:  "queue timeout",
+			group: NewConcurrencyGroup(1, WithQueue(100*time.Millisecond)),
+			check: func(t *testing.T, g Group) {
+				ctx := context.Background()
+				now := time.Now()
+				if !g.inc(ctx, now, 0, yes) {
+					t.Fatal("inc() should pass")
+				}
+				if g.inc(ctx, now, 0, yes) {
+					t.Fatal("inc() should time out")
+				}
+			},
+		},
+		{
+			name:  "queue until deadline",
+			group: NewConcurrencyGroup(1, WithQueue(0)),
+			check: func(t *testing.T, g Group) {
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+				now := time.Now()
+				if !g.inc(ctx, now, 0, yes) {
+					t.Fatal("inc() should pass")
+				}
+				if g.inc(ctx, now, 0, yes) {
+					t.Fatal("inc() should stop at deadline")
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, tt.group)
+		})
+	}
+}
+
+func Test_priorityGroup(t *testing.T) {
+	g := NewPriorityGroup(2, 100*time.Millisecond)
+	ctx := context.Background()
+	now := time.Now()
+
+	var mu sync.Mutex
+	var admitted []int
+	results := make(chan bool, 4)
+	for _, priority := range []int{1, 3, 2, 4} {
+		priority := priority
+		go func() {
+			results <- g.inc(ctx, now, priority, func() bool {
+				mu.Lock()
+				defer mu.Unlock()
+				admitted = append(admitted, priority)
+				return true
+			})
+		}()
+	}
+	passed := 0
+	for i := 0; i < 4; i++ {
+		if <-results {
+			passed++
+		}
+	}
+	if passed != 2 {
+		t.Fatalf("passed = %v, want 2", passed)
+	}
+	if !reflect.DeepEqual(admitted, []int{4, 3}) {
+		t.Fatalf("admitted = %v, want [4 3]", admitted)
+	}
+
+	if g.inc(ctx, now, 5, func() bool { return true }) {
+		t.Fatal("inc() after window should still be limited by plan time")
+	}
+	if !g.inc(ctx, now.Add(time.Second), 0, func() bool { return true }) {
+		t.Fatal("inc() of another plan time should pass")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -16,8 +18,19 @@ type JobMeta interface {
 	Key() string
 	// Spec returns the spec of the job.
 	Spec() string
-	// Statistics returns statistics info of the job.
+	// Statistics returns a snapshot of statistics info of the job.
 	Statistics() Statistics
+	// NextAt returns when the job will run next time, zero if the cron is not running.
+	NextAt() time.Time
+	// PrevAt returns when the job ran last time, zero if it has not run since the cron started.
+	PrevAt() time.Time
+	// RecentStatistics returns statistics info of the job in the recent window, like 5 minutes or an hour,
+	// with minute granularity up to an hour and hour granularity up to 24 hours.
+	RecentStatistics(window time.Duration) Statistics
+	// Latency returns quantiles of durations of recent tasks run by the instance.
+	Latency() Latency
+	// LastResult returns results of the latest tasks run by the instance.
+	LastResult() LastResult
 }
 
 type innerJob struct {
@@ -32,8 +45,13 @@ type innerJob struct {
 	retryTimes    int
 	retryInterval RetryInterval
 	noMutex       bool
-	statistics    Statistics
+	statistics    statisticsRecorder
+	latency       latencyRecorder
 	group         Group
+	priority      int
+	paused        int32
+	misfire       MisfirePolicy
+	mu            sync.RWMutex // protects entryID and spec, which could be changed by Cron.UpdateJobSpec
 }
 
 // Key implements JobMeta.Key.
@@ -43,52 +61,157 @@ func (j *innerJob) Key() string {
 
 // Spec implements JobMeta.Spec.
 func (j *innerJob) Spec() string {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
 	return j.spec
 }
 
 // Statistics implements JobMeta.Statistics.
 func (j *innerJob) Statistics() Statistics {
-	return j.statistics
+	return j.statistics.snapshot()
+}
+
+// NextAt implements JobMeta.NextAt.
+func (j *innerJob) NextAt() time.Time {
+	// the entry keeps the stale next time after the cron stops
+	if j.cron.State() != StateRunning {
+		return time.Time{}
+	}
+	return j.entry().Next
+}
+
+// PrevAt implements JobMeta.PrevAt.
+func (j *innerJob) PrevAt() time.Time {
+	return j.entry().Prev
+}
+
+// RecentStatistics implements JobMeta.RecentStatistics.
+func (j *innerJob) RecentStatistics(window time.Duration) Statistics {
+	return j.statistics.recent(window)
+}
+
+// Latency implements JobMeta.Latency.
+func (j *innerJob) Latency() Latency {
+	return j.latency.latency()
+}
+
+// LastResult implements JobMeta.LastResult.
+func (j *innerJob) LastResult() LastResult {
+	return j.latency.lastResult()
+}
+
+func (j *innerJob) setPaused(paused bool) {
+	var v int32
+	if paused {
+		v = 1
+	}
+	atomic.StoreInt32(&j.paused, v)
+}
+
+func (j *innerJob) isPaused() bool {
+	return atomic.LoadInt32(&j.paused) == 1
+}
+
+func (j *innerJob) pausedKey() string {
+	return fmt.Sprintf("dcron:%s.%s/paused", j.cron.key, j.key)
+}
+
+func (j *innerJob) entry() cron.Entry {
+	j.mu.RLock()
+	entryID := j.entryID
+	j.mu.RUnlock()
+
+	return j.entryGetter.Entry(entryID)
 }
 
 func (j *innerJob) Run() {
 	c := j.cron
-	entry := j.entryGetter.Entry(j.entryID)
+	entry := j.entry()
+	if !entry.Valid() || entry.Prev.IsZero() {
+		// the entry has been removed by Cron.RemoveJob or replaced by Cron.UpdateJobSpec
+		return
+	}
 	planAt := entry.Prev
 	nextAt := entry.Next
-	key := fmt.Sprintf("dcron:%s.%s@%d", c.key, j.key, planAt.Unix())
 
-	task := Task{
-		Key:        key,
+	ctx, cancel := context.WithDeadline(c.runningContext(), nextAt)
+	defer cancel()
+
+	if j.misfire != nil && c.store != nil {
+		_ = c.store.Set(ctx, j.lastPlanKey(), strconv.FormatInt(planAt.Unix(), 10))
+	}
+
+	j.exec(ctx, Task{
+		Key:        fmt.Sprintf("dcron:%s.%s@%d", c.key, j.key, planAt.Unix()),
 		Cron:       c,
 		Job:        j,
 		PlanAt:     planAt,
 		TriedTimes: 0,
-	}
-	atomic.AddInt64(&j.statistics.TotalTask, 1)
+	})
+}
 
-	parentCtx := c.context
-	if parentCtx == nil {
-		parentCtx = context.Background()
-	}
+// exec processes the task with the context, and returns the task after it has finished.
+func (j *innerJob) exec(ctx context.Context, task Task) Task {
+	c := j.cron
+	planAt := task.PlanAt
+	parentCtx := c.runningContext()
+	j.statistics.add(Statistics{TotalTask: 1})
 
-	ctx, cancel := context.WithDeadline(context.WithValue(parentCtx, keyContextTask, task), nextAt)
-	defer cancel()
+	ctx = context.WithValue(ctx, keyContextTask, task)
+	ctx, endTask := c.instrument.StartTask(ctx, task)
 
-	if j.before != nil && j.before(task) {
+	if !task.Manual && c.isPaused(ctx, j) || j.before != nil && j.before(task) {
 		task.Skipped = true
-		atomic.AddInt64(&j.statistics.SkippedTask, 1)
+		j.statistics.add(Statistics{SkippedTask: 1})
 	}
 
 	if !task.Skipped {
+		admitted := c.admit(task)
+		if admitted {
+			var id uint64
+			if id, admitted = c.tracker.begin(task.Key); admitted {
+				defer c.tracker.end(id)
+			}
+		}
+		if !admitted {
+			task.Declined = true
+			j.statistics.add(Statistics{DeclinedTask: 1})
+		}
+	}
+
+	if !task.Skipped && !task.Declined {
 		checkAtomic := func() bool {
-			return j.noMutex || j.cron.atomic == nil || j.cron.atomic.SetIfNotExists(ctx, task.Key, c.hostname)
+			if c.tracker.isDraining() {
+				return false
+			}
+			if j.noMutex || c.atomic == nil {
+				return true
+			}
+			acquireCtx, endAcquire := c.instrument.StartAcquire(ctx, task)
+			acquireAt := time.Now()
+			acquired := c.atomic.SetIfNotExists(acquireCtx, task.Key, c.hostname)
+			j.latency.observeAcquire(time.Since(acquireAt))
+			endAcquire(acquired)
+			return acquired
 		}
-		needExec := false
+		acquire := checkAtomic
 		if j.group != nil {
-			needExec = j.group.inc(planAt, checkAtomic)
+			acquire = func() bool {
+				return j.group.inc(ctx, planAt, j.priority, checkAtomic)
+			}
+		}
+		needExec := false
+		noSlot := false
+		if c.limiter != nil {
+			slotted := false
+			needExec = c.limiter.inc(ctx, planAt, j.priority, func() bool {
+				slotted = true
+				return acquire()
+			})
+			noSlot = !slotted
 		} else {
-			needExec = checkAtomic()
+			needExec = acquire()
 		}
 
 		if needExec {
@@ -96,35 +219,52 @@ func (j *innerJob) Run() {
 			task.BeginAt = &beginAt
 
 			for i := 0; i < j.retryTimes; i++ {
-				task.Return = safeRun(ctx, j.run)
-				atomic.AddInt64(&j.statistics.TotalRun, 1)
+				runCtx, endRun := c.instrument.StartRun(ctx, task)
+				task.Return = safeRun(runCtx, j.run)
+				endRun(task.Return)
+				j.statistics.add(Statistics{TotalRun: 1})
 				if i > 0 {
-					atomic.AddInt64(&j.statistics.RetriedRun, 1)
+					j.statistics.add(Statistics{RetriedRun: 1})
 				}
 				task.TriedTimes++
 				if task.Return == nil {
-					atomic.AddInt64(&j.statistics.PassedRun, 1)
+					j.statistics.add(Statistics{PassedRun: 1})
 					break
 				}
-				atomic.AddInt64(&j.statistics.FailedRun, 1)
+				j.statistics.add(Statistics{FailedRun: 1})
 				if ctx.Err() != nil {
 					break
 				}
 				if j.retryInterval != nil {
 					interval := j.retryInterval(task.TriedTimes)
-					deadline, _ := ctx.Deadline()
-					if -time.Since(deadline) < interval {
+					if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < interval {
+						break
+					}
+					if !sleep(ctx, interval) {
 						break
 					}
-					time.Sleep(interval)
 				}
 			}
 
 			endAt := time.Now()
 			task.EndAt = &endAt
+			if task.Return != nil && parentCtx.Err() != nil {
+				task.Interrupted = true
+				atomic.AddInt64(&c.interrupted, 1)
+			}
+
+			if j.group != nil {
+				j.group.dec(planAt)
+			}
+			if c.limiter != nil {
+				c.limiter.dec(planAt)
+			}
+		} else if noSlot || c.tracker.isDraining() {
+			task.Declined = true
+			j.statistics.add(Statistics{DeclinedTask: 1})
 		} else {
 			task.Missed = true
-			atomic.AddInt64(&j.statistics.MissedTask, 1)
+			j.statistics.add(Statistics{MissedTask: 1})
 		}
 	}
 
@@ -132,19 +272,59 @@ func (j *innerJob) Run() {
 		j.after(task)
 	}
 
-	if !task.Skipped && !task.Missed {
+	if !task.Skipped && !task.Missed && !task.Declined {
 		if task.Return == nil {
-			atomic.AddInt64(&j.statistics.PassedTask, 1)
+			j.statistics.add(Statistics{PassedTask: 1})
 		} else {
-			atomic.AddInt64(&j.statistics.FailedTask, 1)
+			j.statistics.add(Statistics{FailedTask: 1})
 		}
+		j.latency.observeTask(task)
+	}
+
+	for _, hook := range c.taskHooks {
+		hook(task)
+	}
+	endTask(task)
+
+	if c.taskStore != nil {
+		saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), saveTimeout)
+		_ = c.taskStore.Save(saveCtx, task.Record())
+		cancel()
+	}
+	return task
+}
+
+// sleep pauses for the duration, or returns false if the context is done before that.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// PanicError is returned as Task.Return when the job panics.
+type PanicError struct {
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace of the panicking goroutine
+}
+
+// Error implements error.
+func (e *PanicError) Error() string {
+	if len(e.Stack) == 0 {
+		return fmt.Sprint(e.Value)
 	}
+	return fmt.Sprintf("%v: %s", e.Value, e.Stack)
 }
 
 func safeRun(ctx context.Context, run RunFunc) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = fmt.Errorf("%v: %s", r, debug.Stack())
+			err = &PanicError{Value: r, Stack: debug.Stack()}
 		}
 	}()
 	return run(ctx)
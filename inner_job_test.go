@@ -95,6 +95,7 @@ func Test_innerJob_Run(t *testing.T) {
 		after         AfterFunc
 		retryTimes    int
 		retryInterval RetryInterval
+		paused        int32
 	}
 	tests := []struct {
 		name       string
@@ -163,6 +164,39 @@ func Test_innerJob_Run(t *testing.T) {
 				RetriedRun:  0,
 			},
 		},
+		{
+			name: "paused",
+			fields: fields{
+				cron:        NewCron(WithAtomic(atomic)),
+				entryID:     1,
+				entryGetter: mockEntryGetter,
+				before: func(task Task) (skip bool) {
+					t.Fatal("should not be called")
+					return false
+				},
+				run: func(ctx context.Context) error {
+					return nil
+				},
+				after: func(task Task) {
+					if !task.Skipped {
+						t.Fatal(task.Skipped)
+					}
+				},
+				retryTimes: 1,
+				paused:     1,
+			},
+			statistics: Statistics{
+				TotalTask:   1,
+				PassedTask:  0,
+				FailedTask:  0,
+				SkippedTask: 1,
+				MissedTask:  0,
+				TotalRun:    0,
+				PassedRun:   0,
+				FailedRun:   0,
+				RetriedRun:  0,
+			},
+		},
 		{
 			name: "retry",
 			fields: fields{
@@ -301,6 +335,70 @@ func Test_innerJob_Run(t *testing.T) {
 				RetriedRun:  0,
 			},
 		},
+		{
+			name: "overload",
+			fields: fields{
+				cron: func() *Cron {
+					c := NewCron(WithAtomic(atomic), WithMaxConcurrentTasks(1, OverloadSkip))
+					c.limiter.inc(context.Background(), time.Now(), 0, func() bool { return true })
+					return c
+				}(),
+				entryID:     1,
+				entryGetter: mockEntryGetter,
+				run: func(ctx context.Context) error {
+					return nil
+				},
+				after: func(task Task) {
+					if !task.Declined || task.Missed {
+						t.Fatal(task.Declined, task.Missed)
+					}
+				},
+				retryTimes: 1,
+			},
+			statistics: Statistics{
+				TotalTask:    1,
+				PassedTask:   0,
+				FailedTask:   0,
+				SkippedTask:  0,
+				MissedTask:   0,
+				DeclinedTask: 1,
+				TotalRun:     0,
+				PassedRun:    0,
+				FailedRun:    0,
+				RetriedRun:   0,
+			},
+		},
+		{
+			name: "declined",
+			fields: fields{
+				cron: NewCron(WithAtomic(atomic), WithAdmissionCheck(func(task Task) bool {
+					return false
+				})),
+				entryID:     1,
+				entryGetter: mockEntryGetter,
+				run: func(ctx context.Context) error {
+					return nil
+				},
+				after: func(task Task) {
+					if !task.Declined {
+						t.Fatal(task.Declined)
+					}
+				},
+				retryTimes: 1,
+			},
+			statistics: Statistics{
+				TotalTask:    1,
+				PassedTask:   0,
+				FailedTask:   0,
+				SkippedTask:  0,
+				MissedTask:   0,
+				DeclinedTask: 1,
+				TotalRun:     0,
+				PassedRun:    0,
+				FailedRun:    0,
+				RetriedRun:   0,
+			},
+		},
 		{
 			name: "panic by calling",
 			fields: fields{
@@ -314,6 +412,10 @@ func Test_innerJob_Run(t *testing.T) {
 					if !strings.Contains(task.Return.Error(), "not happy") {
 						t.Fatal(task.Return)
 					}
+					var panicErr *PanicError
+					if !errors.As(task.Return, &panicErr) || panicErr.Value != "not happy" || len(panicErr.Stack) == 0 {
+						t.Fatal(task.Return)
+					}
 				},
 				retryTimes: 1,
 			},
@@ -375,6 +477,7 @@ func Test_innerJob_Run(t *testing.T) {
 				after:         tt.fields.after,
 				retryTimes:    tt.fields.retryTimes,
 				retryInterval: tt.fields.retryInterval,
+				paused:        tt.fields.paused,
 			}
 			j.Run()
 			if got := j.Statistics(); got != tt.statistics {
@@ -428,3 +531,73 @@ func Test_innerJob_Spec(t *testing.T) {
 		})
 	}
 }
+
+func Test_innerJob_NextAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockEntryGetter := mock_dcron.NewMockentryGetter(ctrl)
+
+	prev := time.Now().Truncate(time.Second)
+	next := prev.Add(time.Second)
+	mockEntryGetter.EXPECT().
+		Entry(cron.EntryID(1)).
+		Return(cron.Entry{ID: 1, Prev: prev, Next: next}).
+		Times(2)
+
+	j := &innerJob{
+		cron:        &Cron{state: StateRunning},
+		entryID:     1,
+		entryGetter: mockEntryGetter,
+	}
+	if got := j.NextAt(); !got.Equal(next) {
+		t.Errorf("NextAt() = %v, want %v", got, next)
+	}
+	if got := j.PrevAt(); !got.Equal(prev) {
+		t.Errorf("PrevAt() = %v, want %v", got, prev)
+	}
+
+	j.cron.state = StateStopped
+	if got := j.NextAt(); !got.IsZero() {
+		t.Errorf("NextAt() = %v, want zero", got)
+	}
+}
+
+func Test_innerJob_Run_removed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockEntryGetter := mock_dcron.NewMockentryGetter(ctrl)
+
+	prev := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name  string
+		entry cron.Entry
+	}{
+		{
+			name:  "removed",
+			entry: cron.Entry{},
+		},
+		{
+			name:  "replaced",
+			entry: cron.Entry{ID: 2, Next: prev.Add(time.Second)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockEntryGetter.EXPECT().Entry(gomock.Any()).Return(tt.entry)
+			j := &innerJob{
+				cron:        NewCron(),
+				entryID:     1,
+				entryGetter: mockEntryGetter,
+				run: func(ctx context.Context) error {
+					t.Fatal("should not run")
+					return nil
+				},
+				retryTimes: 1,
+			}
+			j.Run()
+			if got := j.Statistics(); got != (Statistics{}) {
+				t.Errorf("Statistics() = %+v, want zero", got)
+			}
+		})
+	}
+}
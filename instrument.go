@@ -0,0 +1,31 @@
+package dcron
+
+import "context"
+
+// Instrument observes steps of processing tasks, it could be used to integrate with tracing systems.
+// Every Start method returns the context for the step and a function to be called when the step ends.
+type Instrument interface {
+	// StartTask is called when the task begins to be processed,
+	// end is called with the processed task after AfterFunc and TaskHooks.
+	StartTask(ctx context.Context, task Task) (context.Context, func(task Task))
+	// StartAcquire is called before acquiring the task by Atomic.SetIfNotExists,
+	// end is called with whether the task is acquired.
+	StartAcquire(ctx context.Context, task Task) (context.Context, func(acquired bool))
+	// StartRun is called before each run of the job, the returned context is passed to the RunFunc,
+	// end is called with what the RunFunc returns.
+	StartRun(ctx context.Context, task Task) (context.Context, func(err error))
+}
+
+type nopInstrument struct{}
+
+func (nopInstrument) StartTask(ctx context.Context, _ Task) (context.Context, func(task Task)) {
+	return ctx, func(Task) {}
+}
+
+func (nopInstrument) StartAcquire(ctx context.Context, _ Task) (context.Context, func(acquired bool)) {
+	return ctx, func(bool) {}
+}
+
+func (nopInstrument) StartRun(ctx context.Context, _ Task) (context.Context, func(err error)) {
+	return ctx, func(error) {}
+}
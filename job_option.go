@@ -63,3 +63,20 @@ func WithGroup(group Group) JobOption {
 		job.group = group
 	}
 }
+
+// WithPriority specifies the priority of the job, higher is more preferred.
+// It works with the group created by NewPriorityGroup.
+func WithPriority(priority int) JobOption {
+	return func(job *innerJob) {
+		job.priority = priority
+	}
+}
+
+// WithMisfirePolicy specifies which missed plan times should be run when the cron starts,
+// after all instances of the cron were down.
+// It works only if the cron has a Store, which is used to persist the last plan time of the job.
+func WithMisfirePolicy(policy MisfirePolicy) JobOption {
+	return func(job *innerJob) {
+		job.misfire = policy
+	}
+}
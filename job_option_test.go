@@ -164,3 +164,67 @@ func TestWithNoMutex(t *testing.T) {
 		})
 	}
 }
+
+func TestWithPriority(t *testing.T) {
+	type args struct {
+		priority int
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(t *testing.T, option JobOption)
+	}{
+		{
+			name: "regular",
+			args: args{
+				priority: 10,
+			},
+			check: func(t *testing.T, option JobOption) {
+				j := &innerJob{}
+				option(j)
+				if j.priority != 10 {
+					t.Fatal(j.priority)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithPriority(tt.args.priority)
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestWithMisfirePolicy(t *testing.T) {
+	policy := MisfireRunOnce()
+
+	type args struct {
+		policy MisfirePolicy
+	}
+	tests := []struct {
+		name  string
+		args  args
+		check func(t *testing.T, option JobOption)
+	}{
+		{
+			name: "regular",
+			args: args{
+				policy: policy,
+			},
+			check: func(t *testing.T, option JobOption) {
+				j := &innerJob{}
+				option(j)
+				if fmt.Sprintf("%p", j.misfire) != fmt.Sprintf("%p", policy) {
+					t.Fatal()
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithMisfirePolicy(tt.args.policy)
+			tt.check(t, got)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package dcron
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySamples is how many recent samples are kept to calculate quantiles.
+const latencySamples = 1024
+
+// Quantiles summarizes recent samples of a duration.
+type Quantiles struct {
+	Samples int           // Number of samples, at most the latest 1024 samples are kept
+	P50     time.Duration // 50th percentile
+	P90     time.Duration // 90th percentile
+	P99     time.Duration // 99th percentile
+	Max     time.Duration // Maximum
+}
+
+// Latency records quantiles of durations of recent tasks.
+type Latency struct {
+	Run     Quantiles // From the begin time to the end time of tasks, including retries
+	Lag     Quantiles // From the plan time to the begin time of scheduled tasks, excluding manual and misfired ones
+	Acquire Quantiles // How long Atomic.SetIfNotExists takes
+}
+
+// LastResult records results of the latest tasks run by the instance.
+type LastResult struct {
+	PassedAt time.Time // When the latest passed task ended, zero if there is none
+	FailedAt time.Time // When the latest failed task ended, zero if there is none
+	Error    error     // Error returned by the latest failed task, nil if there is none
+}
+
+// durationWindow keeps recent samples of a duration in a ring buffer.
+type durationWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+func (w *durationWindow) observe(d time.Duration) {
+	if len(w.samples) < latencySamples {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencySamples
+}
+
+func (w *durationWindow) quantiles() Quantiles {
+	n := len(w.samples)
+	if n == 0 {
+		return Quantiles{}
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, w.samples)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	rank := func(q float64) time.Duration {
+		// nearest-rank method
+		return sorted[int(math.Ceil(q*float64(n)))-1]
+	}
+	return Quantiles{
+		Samples: n,
+		P50:     rank(0.5),
+		P90:     rank(0.9),
+		P99:     rank(0.99),
+		Max:     sorted[n-1],
+	}
+}
+
+// latencyRecorder records latency and results of recent tasks, it is safe for concurrent use.
+type latencyRecorder struct {
+	mu      sync.Mutex
+	run     durationWindow
+	lag     durationWindow
+	acquire durationWindow
+	last    LastResult
+}
+
+// observeTask records the task which has been run by the instance.
+func (r *latencyRecorder) observeTask(task Task) {
+	if task.BeginAt == nil || task.EndAt == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !task.Manual && !task.Misfired {
+		// plan times of manual and misfired tasks could be long ago, they are not lags of scheduling
+		r.lag.observe(task.BeginAt.Sub(task.PlanAt))
+	}
+	r.run.observe(task.EndAt.Sub(*task.BeginAt))
+	if task.Return == nil {
+		r.last.PassedAt = *task.EndAt
+	} else {
+		r.last.FailedAt = *task.EndAt
+		r.last.Error = task.Return
+	}
+}
+
+func (r *latencyRecorder) observeAcquire(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.acquire.observe(d)
+}
+
+func (r *latencyRecorder) latency() Latency {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Latency{
+		Run:     r.run.quantiles(),
+		Lag:     r.lag.quantiles(),
+		Acquire: r.acquire.quantiles(),
+	}
+}
+
+func (r *latencyRecorder) lastResult() LastResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.last
+}
@@ -0,0 +1,141 @@
+package dcron
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gochore/dcron/mock_dcron"
+	"go.uber.org/mock/gomock"
+)
+
+func Test_durationWindow_quantiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		want    Quantiles
+	}{
+		{
+			name:    "empty",
+			samples: nil,
+			want:    Quantiles{},
+		},
+		{
+			name:    "one sample",
+			samples: []time.Duration{time.Second},
+			want:    Quantiles{Samples: 1, P50: time.Second, P90: time.Second, P99: time.Second, Max: time.Second},
+		},
+		{
+			name: "unsorted",
+			samples: func() []time.Duration {
+				var ret []time.Duration
+				for i := 100; i > 0; i-- {
+					ret = append(ret, time.Duration(i)*time.Millisecond)
+				}
+				return ret
+			}(),
+			want: Quantiles{
+				Samples: 100,
+				P50:     50 * time.Millisecond,
+				P90:     90 * time.Millisecond,
+				P99:     99 * time.Millisecond,
+				Max:     100 * time.Millisecond,
+			},
+		},
+		{
+			name: "only recent samples",
+			samples: func() []time.Duration {
+				var ret []time.Duration
+				for i := 0; i < latencySamples; i++ {
+					ret = append(ret, time.Hour)
+				}
+				for i := 0; i < latencySamples; i++ {
+					ret = append(ret, time.Second)
+				}
+				return ret
+			}(),
+			want: Quantiles{Samples: latencySamples, P50: time.Second, P90: time.Second, P99: time.Second, Max: time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &durationWindow{}
+			for _, d := range tt.samples {
+				w.observe(d)
+			}
+			if got := w.quantiles(); got != tt.want {
+				t.Errorf("quantiles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobMeta_LastResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	atomic := mock_dcron.NewMockAtomic(ctrl)
+	atomic.EXPECT().SetIfNotExists(gomock.Any(), gomock.Any(), gomock.Any()).Return(true).Times(2)
+
+	c := NewCron(WithKey("test_cron"), WithAtomic(atomic))
+	fail := false
+	if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		if fail {
+			return errors.New("failed")
+		}
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+	j := c.Jobs()[0]
+	if got := j.LastResult(); got != (LastResult{}) {
+		t.Fatalf("LastResult() = %+v, want zero", got)
+	}
+
+	passed, err := c.Trigger(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+	fail = true
+	failed, err := c.Trigger(context.Background(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := j.LastResult()
+	if !got.PassedAt.Equal(*passed.EndAt) || !got.FailedAt.Equal(*failed.EndAt) || got.Error == nil || got.Error.Error() != "failed" {
+		t.Fatalf("LastResult() = %+v", got)
+	}
+
+	latency := j.Latency()
+	// triggered tasks are manual, their lags are not observed
+	if latency.Run.Samples != 2 || latency.Lag.Samples != 0 || latency.Acquire.Samples != 2 {
+		t.Fatalf("Latency() = %+v", latency)
+	}
+	if latency.Run.P50 < 10*time.Millisecond || latency.Run.Max < latency.Run.P50 {
+		t.Fatalf("Latency() = %+v", latency)
+	}
+}
+
+func Test_latencyRecorder_observeTask(t *testing.T) {
+	planAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	beginAt := planAt.Add(time.Second)
+	endAt := beginAt.Add(time.Second)
+
+	r := &latencyRecorder{}
+	r.observeTask(Task{PlanAt: planAt, BeginAt: &beginAt, EndAt: &endAt})
+	r.observeTask(Task{PlanAt: planAt.Add(-24 * time.Hour), BeginAt: &beginAt, EndAt: &endAt, Manual: true})
+	r.observeTask(Task{PlanAt: planAt.Add(-24 * time.Hour), BeginAt: &beginAt, EndAt: &endAt, Misfired: true})
+	r.observeTask(Task{PlanAt: planAt, Skipped: true})
+
+	latency := r.latency()
+	if latency.Run.Samples != 3 || latency.Run.Max != time.Second {
+		t.Fatalf("Run = %+v", latency.Run)
+	}
+	if latency.Lag.Samples != 1 || latency.Lag.Max != time.Second {
+		t.Fatalf("Lag = %+v", latency.Lag)
+	}
+}
@@ -0,0 +1,88 @@
+// Package expvar publishes jobs and statistics of dcron under expvar,
+// or serves them as JSON with an http.Handler, for services not running Prometheus.
+package expvar
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"time"
+
+	"github.com/gochore/dcron"
+)
+
+// Cron is the status of a cron.
+type Cron struct {
+	Key          string           `json:"key"`
+	Hostname     string           `json:"hostname"`
+	State        string           `json:"state"`
+	RunningTasks int              `json:"running_tasks"`
+	Statistics   dcron.Statistics `json:"statistics"`
+	Jobs         []Job            `json:"jobs"`
+}
+
+// Job is the status of a job.
+type Job struct {
+	Key        string           `json:"key"`
+	Spec       string           `json:"spec"`
+	NextAt     *time.Time       `json:"next_at"` // nil if the cron is not running
+	PrevAt     *time.Time       `json:"prev_at"` // nil if the job has not run since the cron started
+	Statistics dcron.Statistics `json:"statistics"`
+}
+
+// Status returns the status of the crons.
+func Status(crons ...dcron.CronMeta) []Cron {
+	ret := make([]Cron, 0, len(crons))
+	for _, c := range crons {
+		jobs := c.Jobs()
+		status := Cron{
+			Key:          c.Key(),
+			Hostname:     c.Hostname(),
+			State:        c.State().String(),
+			RunningTasks: c.RunningTasks(),
+			Jobs:         make([]Job, 0, len(jobs)),
+		}
+		// sum statistics of the jobs rather than calling c.Statistics, so they are consistent
+		for _, j := range jobs {
+			job := Job{
+				Key:        j.Key(),
+				Spec:       j.Spec(),
+				NextAt:     timePtr(j.NextAt()),
+				PrevAt:     timePtr(j.PrevAt()),
+				Statistics: j.Statistics(),
+			}
+			status.Statistics = status.Statistics.Add(job.Statistics)
+			status.Jobs = append(status.Jobs, job)
+		}
+		ret = append(ret, status)
+	}
+	return ret
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// Publish publishes the status of the crons under expvar with the name,
+// like expvar.Publish, it panics if the name is already registered.
+func Publish(name string, crons ...dcron.CronMeta) {
+	expvar.Publish(name, expvar.Func(func() any {
+		return Status(crons...)
+	}))
+}
+
+// Handler returns an http.Handler serving the status of the crons as JSON.
+func Handler(crons ...dcron.CronMeta) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.Marshal(Status(crons...))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write(data)
+	})
+}
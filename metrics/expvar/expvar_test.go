@@ -0,0 +1,113 @@
+package expvar
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gochore/dcron"
+)
+
+func newTestCron(t *testing.T) *dcron.Cron {
+	c := dcron.NewCron(dcron.WithKey("test_cron"), dcron.WithHostname("test_hostname"))
+	if err := c.AddJobs(
+		dcron.NewJob("test1", "0 0 0 1 1 *", func(ctx context.Context) error {
+			return nil
+		}),
+		dcron.NewJob("test2", "*/5 * * * * *", func(ctx context.Context) error {
+			return nil
+		}),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Trigger(context.Background(), "test1"); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestStatus(t *testing.T) {
+	c := newTestCron(t)
+
+	got := Status(c)
+	if len(got) != 1 {
+		t.Fatalf("len(Status()) = %v, want 1", len(got))
+	}
+	status := got[0]
+	if status.Key != "test_cron" || status.Hostname != "test_hostname" || status.State != "created" || len(status.Jobs) != 2 {
+		t.Fatalf("Status() = %+v", status)
+	}
+	if want := (dcron.Statistics{TotalTask: 1, PassedTask: 1, TotalRun: 1, PassedRun: 1}); status.Statistics != want {
+		t.Fatalf("Statistics = %+v, want %+v", status.Statistics, want)
+	}
+	if job := status.Jobs[1]; job.Key != "test2" || job.Spec != "*/5 * * * * *" || job.NextAt != nil || job.PrevAt != nil {
+		t.Fatalf("Jobs[1] = %+v", job)
+	}
+
+	c.Start()
+	status = Status(c)[0]
+	if status.State != "running" {
+		t.Fatalf("State = %v", status.State)
+	}
+	for _, job := range status.Jobs {
+		if job.NextAt == nil {
+			t.Fatalf("NextAt of %v should not be nil", job.Key)
+		}
+	}
+
+	<-c.Stop().Done()
+	status = Status(c)[0]
+	if status.State != "stopped" {
+		t.Fatalf("State = %v", status.State)
+	}
+	for _, job := range status.Jobs {
+		if job.NextAt != nil {
+			t.Fatalf("NextAt of %v should be nil", job.Key)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	c := newTestCron(t)
+
+	recorder := httptest.NewRecorder()
+	Handler(c).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Code = %v", recorder.Code)
+	}
+	if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Fatalf("Content-Type = %v", got)
+	}
+
+	var got []Cron
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Key != "test_cron" || len(got[0].Jobs) != 2 || got[0].Jobs[0].Statistics.PassedTask != 1 {
+		t.Fatalf("Handler() = %+v", got)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	c := newTestCron(t)
+	// expvar panics if a name is published twice, so use a unique one for every run, like with -count=2
+	name := fmt.Sprintf("dcron_test_%d", time.Now().UnixNano())
+	Publish(name, c)
+
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatalf("%s should be published", name)
+	}
+	var got []Cron
+	if err := json.Unmarshal([]byte(v.String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Key != "test_cron" || got[0].Statistics.TotalTask != 1 {
+		t.Fatalf("Publish() = %+v", got)
+	}
+}
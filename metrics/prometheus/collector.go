@@ -0,0 +1,186 @@
+// Package prometheus provides a collector exporting statistics and task latency of dcron to Prometheus.
+package prometheus
+
+import (
+	"sync"
+
+	"github.com/gochore/dcron"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "dcron"
+
+// Option represents a modification to the default behavior of a Collector.
+type Option func(c *Collector)
+
+// WithLagBuckets overrides buckets of the schedule lag histogram, in seconds.
+func WithLagBuckets(buckets []float64) Option {
+	return func(c *Collector) {
+		c.lagBuckets = buckets
+	}
+}
+
+// WithDurationBuckets overrides buckets of the run duration histogram, in seconds.
+func WithDurationBuckets(buckets []float64) Option {
+	return func(c *Collector) {
+		c.durationBuckets = buckets
+	}
+}
+
+// WithTriesBuckets overrides buckets of the tried times histogram.
+func WithTriesBuckets(buckets []float64) Option {
+	return func(c *Collector) {
+		c.triesBuckets = buckets
+	}
+}
+
+// Collector is a prometheus.Collector exporting counters of Statistics per cron and per job,
+// and histograms of schedule lag, run duration and tried times of tasks.
+//
+// Counters are read from crons added by Collector.Watch,
+// while histograms are observed by Collector.Observe, which should be specified by dcron.WithTaskHook.
+type Collector struct {
+	mu    sync.RWMutex
+	crons []dcron.CronMeta
+
+	lagBuckets      []float64
+	durationBuckets []float64
+	triesBuckets    []float64
+
+	cronTasks   *prometheus.Desc
+	cronRuns    *prometheus.Desc
+	cronRetries *prometheus.Desc
+	jobTasks    *prometheus.Desc
+	jobRuns     *prometheus.Desc
+	jobRetries  *prometheus.Desc
+
+	lag      *prometheus.HistogramVec
+	duration *prometheus.HistogramVec
+	tries    *prometheus.HistogramVec
+}
+
+// NewCollector returns a Collector with the options.
+func NewCollector(options ...Option) *Collector {
+	c := &Collector{
+		lagBuckets:      prometheus.DefBuckets,
+		durationBuckets: prometheus.DefBuckets,
+		triesBuckets:    prometheus.LinearBuckets(1, 1, 10),
+	}
+	for _, option := range options {
+		option(c)
+	}
+
+	cronLabels := []string{"cron", "instance"}
+	jobLabels := []string{"cron", "job", "instance"}
+	c.cronTasks = prometheus.NewDesc(namespace+"_cron_tasks_total",
+		"Number of tasks of the cron's all jobs, by status.", append(cronLabels, "status"), nil)
+	c.cronRuns = prometheus.NewDesc(namespace+"_cron_runs_total",
+		"Number of runs of the cron's all jobs, by status.", append(cronLabels, "status"), nil)
+	c.cronRetries = prometheus.NewDesc(namespace+"_cron_retried_runs_total",
+		"Number of retried runs of the cron's all jobs.", cronLabels, nil)
+	c.jobTasks = prometheus.NewDesc(namespace+"_job_tasks_total",
+		"Number of tasks of the job, by status.", append(jobLabels, "status"), nil)
+	c.jobRuns = prometheus.NewDesc(namespace+"_job_runs_total",
+		"Number of runs of the job, by status.", append(jobLabels, "status"), nil)
+	c.jobRetries = prometheus.NewDesc(namespace+"_job_retried_runs_total",
+		"Number of retried runs of the job.", jobLabels, nil)
+
+	c.lag = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_schedule_lag_seconds",
+		Help:      "Duration from the plan time to the begin time of scheduled tasks, excluding manual and misfired ones.",
+		Buckets:   c.lagBuckets,
+	}, jobLabels)
+	c.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_run_duration_seconds",
+		Help:      "Duration from the begin time to the end time of tasks, including retries.",
+		Buckets:   c.durationBuckets,
+	}, jobLabels)
+	c.tries = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_tried_times",
+		Help:      "How many times the job was run for tasks.",
+		Buckets:   c.triesBuckets,
+	}, jobLabels)
+
+	return c
+}
+
+// Watch adds crons whose statistics should be exported.
+func (c *Collector) Watch(crons ...dcron.CronMeta) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.crons = append(c.crons, crons...)
+}
+
+// Observe records latency of the task, it should be specified by dcron.WithTaskHook.
+// Tasks which were not run by the instance are ignored.
+func (c *Collector) Observe(task dcron.Task) {
+	if task.Cron == nil || task.Job == nil || task.BeginAt == nil {
+		return
+	}
+	labels := prometheus.Labels{
+		"cron":     task.Cron.Key(),
+		"job":      task.Job.Key(),
+		"instance": task.Cron.Hostname(),
+	}
+	if !task.Manual && !task.Misfired {
+		c.lag.With(labels).Observe(task.BeginAt.Sub(task.PlanAt).Seconds())
+	}
+	if task.EndAt != nil {
+		c.duration.With(labels).Observe(task.EndAt.Sub(*task.BeginAt).Seconds())
+	}
+	c.tries.With(labels).Observe(float64(task.TriedTimes))
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.cronTasks
+	ch <- c.cronRuns
+	ch <- c.cronRetries
+	ch <- c.jobTasks
+	ch <- c.jobRuns
+	ch <- c.jobRetries
+	c.lag.Describe(ch)
+	c.duration.Describe(ch)
+	c.tries.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	crons := c.crons
+	c.mu.RUnlock()
+
+	for _, cron := range crons {
+		labels := []string{cron.Key(), cron.Hostname()}
+		collectStatistics(ch, cron.Statistics(), c.cronTasks, c.cronRuns, c.cronRetries, labels)
+		for _, job := range cron.Jobs() {
+			labels := []string{cron.Key(), job.Key(), cron.Hostname()}
+			collectStatistics(ch, job.Statistics(), c.jobTasks, c.jobRuns, c.jobRetries, labels)
+		}
+	}
+	c.lag.Collect(ch)
+	c.duration.Collect(ch)
+	c.tries.Collect(ch)
+}
+
+func collectStatistics(ch chan<- prometheus.Metric, s dcron.Statistics, tasks, runs, retries *prometheus.Desc, labels []string) {
+	counter := func(desc *prometheus.Desc, v int64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v), labels...)
+	}
+	with := func(status string) []string {
+		return append(labels[:len(labels):len(labels)], status)
+	}
+
+	counter(tasks, s.PassedTask, with("passed")...)
+	counter(tasks, s.FailedTask, with("failed")...)
+	counter(tasks, s.SkippedTask, with("skipped")...)
+	counter(tasks, s.MissedTask, with("missed")...)
+	counter(tasks, s.DeclinedTask, with("declined")...)
+	counter(runs, s.PassedRun, with("passed")...)
+	counter(runs, s.FailedRun, with("failed")...)
+	counter(retries, s.RetriedRun, labels...)
+}
@@ -0,0 +1,118 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gochore/dcron"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	collector := NewCollector()
+	c := dcron.NewCron(dcron.WithKey("test_cron"), dcron.WithHostname("test_hostname"), dcron.WithTaskHook(collector.Observe))
+	collector.Watch(c)
+
+	if err := c.AddJobs(
+		dcron.NewJob("test1", "* * * * * *", func(ctx context.Context) error {
+			return nil
+		}),
+		dcron.NewJob("test2", "* * * * * *", func(ctx context.Context) error {
+			return errors.New("failed")
+		}, dcron.WithRetryTimes(3)),
+	); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"test1", "test1", "test2"} {
+		if _, err := c.Trigger(context.Background(), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP dcron_cron_runs_total Number of runs of the cron's all jobs, by status.
+# TYPE dcron_cron_runs_total counter
+dcron_cron_runs_total{cron="test_cron",instance="test_hostname",status="failed"} 3
+dcron_cron_runs_total{cron="test_cron",instance="test_hostname",status="passed"} 2
+# HELP dcron_job_tasks_total Number of tasks of the job, by status.
+# TYPE dcron_job_tasks_total counter
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test1",status="declined"} 0
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test1",status="failed"} 0
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test1",status="missed"} 0
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test1",status="passed"} 2
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test1",status="skipped"} 0
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test2",status="declined"} 0
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test2",status="failed"} 1
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test2",status="missed"} 0
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test2",status="passed"} 0
+dcron_job_tasks_total{cron="test_cron",instance="test_hostname",job="test2",status="skipped"} 0
+# HELP dcron_job_retried_runs_total Number of retried runs of the job.
+# TYPE dcron_job_retried_runs_total counter
+dcron_job_retried_runs_total{cron="test_cron",instance="test_hostname",job="test1"} 0
+dcron_job_retried_runs_total{cron="test_cron",instance="test_hostname",job="test2"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"dcron_cron_runs_total", "dcron_job_tasks_total", "dcron_job_retried_runs_total"); err != nil {
+		t.Fatal(err)
+	}
+
+	// triggered tasks are manual, their lags are not observed
+	if got := testutil.CollectAndCount(collector, "dcron_task_schedule_lag_seconds"); got != 0 {
+		t.Fatalf("count of lag = %v, want 0", got)
+	}
+	if got := testutil.CollectAndCount(collector, "dcron_task_run_duration_seconds"); got != 2 {
+		t.Fatalf("count of duration = %v, want 2", got)
+	}
+	if got := testutil.CollectAndCount(collector, "dcron_task_tried_times"); got != 2 {
+		t.Fatalf("count of tries = %v, want 2", got)
+	}
+}
+
+func TestCollector_Observe(t *testing.T) {
+	collector := NewCollector(WithTriesBuckets([]float64{1, 2, 3}))
+	c := dcron.NewCron(dcron.WithKey("test_cron"), dcron.WithHostname("test_hostname"))
+	if err := c.AddJobs(dcron.NewJob("test", "* * * * * *", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.Observe(dcron.Task{Cron: c, Job: c.Jobs()[0], Skipped: true})
+	if got := testutil.CollectAndCount(collector, "dcron_task_tried_times"); got != 0 {
+		t.Fatalf("count of tries = %v, want 0", got)
+	}
+
+	expected := `
+# HELP dcron_task_tried_times How many times the job was run for tasks.
+# TYPE dcron_task_tried_times histogram
+dcron_task_tried_times_bucket{cron="test_cron",instance="test_hostname",job="test",le="1"} 0
+dcron_task_tried_times_bucket{cron="test_cron",instance="test_hostname",job="test",le="2"} 1
+dcron_task_tried_times_bucket{cron="test_cron",instance="test_hostname",job="test",le="3"} 1
+dcron_task_tried_times_bucket{cron="test_cron",instance="test_hostname",job="test",le="+Inf"} 1
+dcron_task_tried_times_sum{cron="test_cron",instance="test_hostname",job="test"} 2
+dcron_task_tried_times_count{cron="test_cron",instance="test_hostname",job="test"} 1
+`
+	beginAt := time.Now()
+	endAt := beginAt.Add(time.Second)
+	collector.Observe(dcron.Task{
+		Cron:       c,
+		Job:        c.Jobs()[0],
+		PlanAt:     beginAt.Truncate(time.Second),
+		BeginAt:    &beginAt,
+		EndAt:      &endAt,
+		TriedTimes: 2,
+	})
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected), "dcron_task_tried_times"); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.CollectAndCount(collector, "dcron_task_schedule_lag_seconds"); got != 1 {
+		t.Fatalf("count of lag = %v, want 1", got)
+	}
+}
@@ -0,0 +1,98 @@
+package dcron
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// maxMisfires is the max number of the latest missed plan times passed to a MisfirePolicy.
+const maxMisfires = 1000
+
+// MisfirePolicy decides which missed plan times should be run, the missed plan times are in order.
+type MisfirePolicy func(missed []time.Time) []time.Time
+
+// MisfireIgnore returns a MisfirePolicy which runs none of the missed plan times.
+func MisfireIgnore() MisfirePolicy {
+	return func(missed []time.Time) []time.Time {
+		return nil
+	}
+}
+
+// MisfireRunOnce returns a MisfirePolicy which runs only the latest missed plan time.
+func MisfireRunOnce() MisfirePolicy {
+	return func(missed []time.Time) []time.Time {
+		if len(missed) == 0 {
+			return nil
+		}
+		return missed[len(missed)-1:]
+	}
+}
+
+// MisfireRunAll returns a MisfirePolicy which runs all missed plan times,
+// but only the latest ones if there are more than limit.
+// It is unlimited if limit is not positive.
+func MisfireRunAll(limit int) MisfirePolicy {
+	return func(missed []time.Time) []time.Time {
+		if limit > 0 && len(missed) > limit {
+			return missed[len(missed)-limit:]
+		}
+		return missed
+	}
+}
+
+func (j *innerJob) lastPlanKey() string {
+	return fmt.Sprintf("dcron:%s.%s/last", j.cron.key, j.key)
+}
+
+// recoverMisfires runs the plan times missed between the last plan time persisted in the store and now,
+// it uses the same keys as scheduled tasks, so a plan time will not be run twice.
+func (j *innerJob) recoverMisfires(ctx context.Context, now time.Time) {
+	c := j.cron
+	value, ok, err := c.store.Get(ctx, j.lastPlanKey())
+	if err != nil || !ok {
+		return
+	}
+	last, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return
+	}
+	schedule, err := specParser.Parse(j.Spec())
+	if err != nil {
+		return
+	}
+
+	now = now.In(c.location)
+	var missed []time.Time
+	for t := schedule.Next(time.Unix(last, 0).In(c.location)); !t.IsZero() && t.Before(now); t = schedule.Next(t) {
+		missed = append(missed, t)
+		if len(missed) > maxMisfires {
+			missed = missed[1:]
+		}
+	}
+	if len(missed) == 0 {
+		return
+	}
+	// move the last plan time forward before running, so the missed plan times will not be found again
+	// if the cron restarts before the next scheduled run
+	_ = c.store.Set(ctx, j.lastPlanKey(), strconv.FormatInt(missed[len(missed)-1].Unix(), 10))
+
+	for _, planAt := range j.misfire(missed) {
+		if ctx.Err() != nil {
+			return
+		}
+		func() {
+			ctx, cancel := context.WithDeadline(ctx, schedule.Next(time.Now().In(c.location)))
+			defer cancel()
+
+			j.exec(ctx, Task{
+				Key:      fmt.Sprintf("dcron:%s.%s@%d", c.key, j.key, planAt.Unix()),
+				Cron:     c,
+				Job:      j,
+				PlanAt:   planAt,
+				Misfired: true,
+			})
+		}()
+	}
+}
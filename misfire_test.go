@@ -0,0 +1,144 @@
+package dcron
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gochore/dcron/mock_dcron"
+
+	"github.com/robfig/cron/v3"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMisfirePolicy(t *testing.T) {
+	now := time.Now()
+	missed := []time.Time{now.Add(-3 * time.Second), now.Add(-2 * time.Second), now.Add(-time.Second)}
+
+	tests := []struct {
+		name   string
+		policy MisfirePolicy
+		missed []time.Time
+		want   []time.Time
+	}{
+		{
+			name:   "ignore",
+			policy: MisfireIgnore(),
+			missed: missed,
+			want:   nil,
+		},
+		{
+			name:   "run once",
+			policy: MisfireRunOnce(),
+			missed: missed,
+			want:   missed[2:],
+		},
+		{
+			name:   "run once without missed",
+			policy: MisfireRunOnce(),
+			missed: nil,
+			want:   nil,
+		},
+		{
+			name:   "run all",
+			policy: MisfireRunAll(0),
+			missed: missed,
+			want:   missed,
+		},
+		{
+			name:   "run all with limit",
+			policy: MisfireRunAll(2),
+			missed: missed,
+			want:   missed[1:],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy(tt.missed); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MisfirePolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_innerJob_recoverMisfires(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := newMapStore(ctrl)
+	atomic := mock_dcron.NewMockAtomic(ctrl)
+
+	c := NewCron(WithKey("test_cron"), WithAtomic(atomic), WithStore(store))
+	var planAts []time.Time
+	if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+		task, _ := TaskFromContext(ctx)
+		if !task.Misfired {
+			t.Errorf("task should be misfired: %+v", task)
+		}
+		planAts = append(planAts, task.PlanAt)
+		return nil
+	}, WithMisfirePolicy(MisfireRunAll(3)))); err != nil {
+		t.Fatal(err)
+	}
+	j := c.job("test")
+
+	last := time.Date(2023, 10, 13, 12, 0, 0, 0, time.UTC)
+	if err := store.Set(context.Background(), "dcron:test_cron.test/last", strconv.FormatInt(last.Unix(), 10)); err != nil {
+		t.Fatal(err)
+	}
+	// every plan time is acquired only once
+	for i := 3; i <= 7; i++ {
+		key := fmt.Sprintf("dcron:test_cron.test@%d", last.Add(time.Duration(i)*time.Second).Unix())
+		atomic.EXPECT().SetIfNotExists(gomock.Any(), key, c.Hostname()).Return(true)
+	}
+
+	// restarted after 5 plan times missed, the latest 3 are run
+	now := last.Add(5500 * time.Millisecond)
+	j.recoverMisfires(context.Background(), now)
+	if len(planAts) != 3 || !planAts[0].Equal(last.Add(3*time.Second)) {
+		t.Fatalf("planAts = %v", planAts)
+	}
+	if value, _, _ := store.Get(context.Background(), "dcron:test_cron.test/last"); value != strconv.FormatInt(last.Add(5*time.Second).Unix(), 10) {
+		t.Fatalf("last plan time = %v", value)
+	}
+
+	// restarted again immediately, nothing is run
+	j.recoverMisfires(context.Background(), now)
+	if len(planAts) != 3 {
+		t.Fatalf("planAts = %v", planAts)
+	}
+
+	// restarted again later, only the new missed plan times are run
+	planAts = nil
+	j.recoverMisfires(context.Background(), now.Add(2*time.Second))
+	if len(planAts) != 2 || !planAts[0].Equal(last.Add(6*time.Second)) {
+		t.Fatalf("planAts = %v", planAts)
+	}
+}
+
+func Test_innerJob_Run_lastPlan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mock_dcron.NewMockStore(ctrl)
+	entryGetter := mock_dcron.NewMockentryGetter(ctrl)
+
+	c := NewCron(WithKey("test_cron"), WithStore(store))
+	if err := c.AddJobs(NewJob("test", "* * * * * *", nil, WithMisfirePolicy(MisfireRunOnce()))); err != nil {
+		t.Fatal(err)
+	}
+	j := c.job("test")
+	j.entryGetter = entryGetter
+
+	planAt := time.Now().Truncate(time.Second)
+	entryGetter.EXPECT().Entry(gomock.Any()).Return(cron.Entry{
+		ID:   j.entryID,
+		Prev: planAt,
+		Next: planAt.Add(time.Second),
+	})
+	store.EXPECT().Get(gomock.Any(), gomock.Any()).Return("", false, nil).Times(2)
+	store.EXPECT().Set(gomock.Any(), "dcron:test_cron.test/last", strconv.FormatInt(planAt.Unix(), 10)).Return(nil)
+
+	j.Run()
+}
@@ -0,0 +1,181 @@
+// Package otel provides a dcron.Instrument integrating with OpenTelemetry tracing and metrics.
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gochore/dcron"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/gochore/dcron/otel"
+
+// Option represents a modification to the default behavior of an Instrument.
+type Option func(i *Instrument)
+
+// WithTracerProvider specifies the TracerProvider to create spans, the global one is used by default.
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(i *Instrument) {
+		i.tracerProvider = provider
+	}
+}
+
+// WithMeterProvider specifies the MeterProvider to record metrics, the global one is used by default.
+func WithMeterProvider(provider metric.MeterProvider) Option {
+	return func(i *Instrument) {
+		i.meterProvider = provider
+	}
+}
+
+// Instrument is a dcron.Instrument which starts a span per task,
+// with child spans for acquiring the task and every run of the job,
+// and records counts, lag and duration of tasks as metrics.
+//
+// The span of a run is injected into the context passed to the RunFunc,
+// so spans started in the job become its children.
+type Instrument struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+
+	tracer   trace.Tracer
+	tasks    metric.Int64Counter
+	lag      metric.Float64Histogram
+	duration metric.Float64Histogram
+}
+
+var _ dcron.Instrument = (*Instrument)(nil)
+
+// NewInstrument returns an Instrument with the options,
+// it could be specified by dcron.WithInstrument.
+func NewInstrument(options ...Option) (*Instrument, error) {
+	i := &Instrument{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+	for _, option := range options {
+		option(i)
+	}
+
+	i.tracer = i.tracerProvider.Tracer(instrumentationName)
+	meter := i.meterProvider.Meter(instrumentationName)
+
+	var err error
+	if i.tasks, err = meter.Int64Counter("dcron.tasks",
+		metric.WithDescription("Number of tasks, by status."),
+		metric.WithUnit("{task}"),
+	); err != nil {
+		return nil, err
+	}
+	if i.lag, err = meter.Float64Histogram("dcron.task.lag",
+		metric.WithDescription("Duration from the plan time to the begin time of scheduled tasks, excluding manual and misfired ones."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	if i.duration, err = meter.Float64Histogram("dcron.task.duration",
+		metric.WithDescription("Duration from the begin time to the end time of tasks, including retries."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// StartTask implements dcron.Instrument.StartTask.
+func (i *Instrument) StartTask(ctx context.Context, task dcron.Task) (context.Context, func(task dcron.Task)) {
+	ctx, span := i.tracer.Start(ctx, "dcron.task", trace.WithAttributes(
+		append(jobAttributes(task),
+			attribute.String("dcron.task.key", task.Key),
+			attribute.String("dcron.task.plan_at", task.PlanAt.Format(time.RFC3339)),
+			attribute.Bool("dcron.task.manual", task.Manual),
+		)...,
+	))
+
+	return ctx, func(task dcron.Task) {
+		defer span.End()
+
+		record := task.Record()
+		span.SetAttributes(
+			attribute.String("dcron.task.status", string(record.Status())),
+			attribute.Int("dcron.task.tried_times", task.TriedTimes),
+		)
+		if task.Return != nil {
+			span.SetStatus(codes.Error, task.Return.Error())
+		}
+
+		attrs := metric.WithAttributes(append(jobAttributes(task),
+			attribute.String("dcron.task.status", string(record.Status())),
+		)...)
+		ctx := context.WithoutCancel(ctx)
+		i.tasks.Add(ctx, 1, attrs)
+		if task.BeginAt != nil {
+			if !task.Manual && !task.Misfired {
+				i.lag.Record(ctx, record.Lag.Seconds(), attrs)
+			}
+			if task.EndAt != nil {
+				i.duration.Record(ctx, record.Duration.Seconds(), attrs)
+			}
+		}
+	}
+}
+
+// StartAcquire implements dcron.Instrument.StartAcquire.
+func (i *Instrument) StartAcquire(ctx context.Context, task dcron.Task) (context.Context, func(acquired bool)) {
+	ctx, span := i.tracer.Start(ctx, "dcron.acquire", trace.WithAttributes(
+		attribute.String("dcron.task.key", task.Key),
+	))
+
+	return ctx, func(acquired bool) {
+		span.SetAttributes(attribute.Bool("dcron.acquired", acquired))
+		span.End()
+	}
+}
+
+// StartRun implements dcron.Instrument.StartRun.
+func (i *Instrument) StartRun(ctx context.Context, task dcron.Task) (context.Context, func(err error)) {
+	ctx, span := i.tracer.Start(ctx, "dcron.run", trace.WithAttributes(
+		attribute.String("dcron.task.key", task.Key),
+		attribute.Int("dcron.run.attempt", task.TriedTimes+1),
+	))
+
+	return ctx, func(err error) {
+		defer span.End()
+
+		if err == nil {
+			return
+		}
+		var panicErr *dcron.PanicError
+		if errors.As(err, &panicErr) {
+			message := fmt.Sprint(panicErr.Value)
+			span.AddEvent("panic", trace.WithAttributes(
+				attribute.String("exception.message", message),
+				attribute.String("exception.stacktrace", string(panicErr.Stack)),
+			))
+			span.SetStatus(codes.Error, message)
+			return
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+func jobAttributes(task dcron.Task) []attribute.KeyValue {
+	var ret []attribute.KeyValue
+	if task.Cron != nil {
+		ret = append(ret,
+			attribute.String("dcron.cron.key", task.Cron.Key()),
+			attribute.String("dcron.instance", task.Cron.Hostname()),
+		)
+	}
+	if task.Job != nil {
+		ret = append(ret, attribute.String("dcron.job.key", task.Job.Key()))
+	}
+	return ret
+}
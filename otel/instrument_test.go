@@ -0,0 +1,162 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gochore/dcron"
+	"github.com/gochore/dcron/mock_dcron"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestInstrument(t *testing.T) (*Instrument, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+	instrument, err := NewInstrument(
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return instrument, recorder, reader
+}
+
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestInstrument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	atomic := mock_dcron.NewMockAtomic(ctrl)
+	atomic.EXPECT().SetIfNotExists(gomock.Any(), gomock.Any(), gomock.Any()).Return(true)
+
+	instrument, recorder, reader := newTestInstrument(t)
+	c := dcron.NewCron(dcron.WithKey("test_cron"), dcron.WithHostname("test_hostname"),
+		dcron.WithAtomic(atomic), dcron.WithInstrument(instrument))
+
+	var runSpans []trace.SpanContext
+	if err := c.AddJobs(dcron.NewJob("test", "* * * * * *", func(ctx context.Context) error {
+		runSpans = append(runSpans, trace.SpanContextFromContext(ctx))
+		if len(runSpans) == 1 {
+			return errors.New("should retry")
+		}
+		return nil
+	}, dcron.WithRetryTimes(2))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Trigger(context.Background(), "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("len(spans) = %v, want 4", len(spans))
+	}
+	acquire, run1, run2, task := spans[0], spans[1], spans[2], spans[3]
+	if task.Name() != "dcron.task" || acquire.Name() != "dcron.acquire" || run1.Name() != "dcron.run" || run2.Name() != "dcron.run" {
+		t.Fatalf("unexpected spans: %v %v %v %v", task.Name(), acquire.Name(), run1.Name(), run2.Name())
+	}
+	for _, span := range []sdktrace.ReadOnlySpan{acquire, run1, run2} {
+		if span.Parent().SpanID() != task.SpanContext().SpanID() {
+			t.Fatalf("span %v should be a child of the task span", span.Name())
+		}
+	}
+	if runSpans[0].SpanID() != run1.SpanContext().SpanID() || runSpans[1].SpanID() != run2.SpanContext().SpanID() {
+		t.Fatal("spans of runs should be injected into contexts of RunFunc")
+	}
+
+	if got := attributeOf(task, "dcron.job.key").AsString(); got != "test" {
+		t.Fatalf("dcron.job.key = %v", got)
+	}
+	if got := attributeOf(task, "dcron.task.status").AsString(); got != "passed" {
+		t.Fatalf("dcron.task.status = %v", got)
+	}
+	if got := attributeOf(task, "dcron.task.tried_times").AsInt64(); got != 2 {
+		t.Fatalf("dcron.task.tried_times = %v", got)
+	}
+	if !attributeOf(acquire, "dcron.acquired").AsBool() {
+		t.Fatal("dcron.acquired should be true")
+	}
+	if got := attributeOf(run2, "dcron.run.attempt").AsInt64(); got != 2 {
+		t.Fatalf("dcron.run.attempt = %v", got)
+	}
+	if run1.Status().Code != codes.Error || len(run1.Events()) != 1 || run1.Events()[0].Name != "exception" {
+		t.Fatalf("unexpected run span: %v %v", run1.Status(), run1.Events())
+	}
+	if run2.Status().Code != codes.Unset {
+		t.Fatalf("unexpected run span: %v", run2.Status())
+	}
+
+	data := metricdata.ResourceMetrics{}
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatal(err)
+	}
+	counts := map[string]int64{}
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch v := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, p := range v.DataPoints {
+					counts[m.Name] += p.Value
+				}
+			case metricdata.Histogram[float64]:
+				for _, p := range v.DataPoints {
+					counts[m.Name] += int64(p.Count)
+				}
+			}
+		}
+	}
+	for _, name := range []string{"dcron.tasks", "dcron.task.duration"} {
+		if counts[name] != 1 {
+			t.Fatalf("count of %v = %v, want 1", name, counts[name])
+		}
+	}
+	// the triggered task is manual, its lag is not recorded
+	if counts["dcron.task.lag"] != 0 {
+		t.Fatalf("count of dcron.task.lag = %v, want 0", counts["dcron.task.lag"])
+	}
+}
+
+func TestInstrument_Panic(t *testing.T) {
+	instrument, recorder, _ := newTestInstrument(t)
+	c := dcron.NewCron(dcron.WithInstrument(instrument))
+	if err := c.AddJobs(dcron.NewJob("test", "* * * * * *", func(ctx context.Context) error {
+		panic("not happy")
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Trigger(context.Background(), "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("len(spans) = %v, want 2", len(spans))
+	}
+	run, task := spans[0], spans[1]
+	if run.Status().Code != codes.Error || run.Status().Description != "not happy" {
+		t.Fatalf("unexpected status: %v", run.Status())
+	}
+	if len(run.Events()) != 1 || run.Events()[0].Name != "panic" {
+		t.Fatalf("unexpected events: %v", run.Events())
+	}
+	if got := attributeOf(task, "dcron.task.status").AsString(); got != "failed" {
+		t.Fatalf("dcron.task.status = %v", got)
+	}
+}
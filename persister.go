@@ -0,0 +1,195 @@
+package dcron
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// persistTimeout limits how long loading or saving statistics could take.
+const persistTimeout = 5 * time.Second
+
+// StatisticsPersister persists statistics of jobs, so they are kept across restarts.
+type StatisticsPersister interface {
+	// Load returns the persisted statistics of the job, ok is false if there is none.
+	Load(ctx context.Context, c CronMeta, jobKey string) (statistics Statistics, ok bool, err error)
+	// Save persists statistics of the cron's jobs, by job key.
+	Save(ctx context.Context, c CronMeta, statistics map[string]Statistics) error
+}
+
+// FileStatisticsPersister is a StatisticsPersister keeping statistics in a local JSON file.
+// The file is read only once, so it should not be modified by others.
+type FileStatisticsPersister struct {
+	path string
+	mu   sync.Mutex
+	all  map[string]map[string]Statistics // statistics in the file by cron key and job key, nil if not read yet
+}
+
+// NewFileStatisticsPersister returns a FileStatisticsPersister keeping statistics in the file,
+// the file will be created if it does not exist.
+func NewFileStatisticsPersister(path string) *FileStatisticsPersister {
+	return &FileStatisticsPersister{
+		path: path,
+	}
+}
+
+// Load implements StatisticsPersister.Load.
+func (p *FileStatisticsPersister) Load(_ context.Context, c CronMeta, jobKey string) (Statistics, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	all, err := p.read()
+	if err != nil {
+		return Statistics{}, false, err
+	}
+	s, ok := all[c.Key()][jobKey]
+	return s, ok, nil
+}
+
+// Save implements StatisticsPersister.Save.
+func (p *FileStatisticsPersister) Save(_ context.Context, c CronMeta, statistics map[string]Statistics) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	read, err := p.read()
+	if err != nil {
+		return err
+	}
+	all := make(map[string]map[string]Statistics, len(read)+1)
+	for k, v := range read {
+		all[k] = v
+	}
+	all[c.Key()] = statistics
+	data, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file and rename it, so the file will not be broken if the process crashes
+	tmp, err := os.CreateTemp(filepath.Dir(p.path), filepath.Base(p.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p.path); err != nil {
+		return err
+	}
+	p.all = all
+	return nil
+}
+
+// read returns statistics in the file, by cron key and job key.
+// The file is read at the first call, and the result is reused by later calls.
+func (p *FileStatisticsPersister) read() (map[string]map[string]Statistics, error) {
+	if p.all != nil {
+		return p.all, nil
+	}
+	ret := map[string]map[string]Statistics{}
+	data, err := os.ReadFile(p.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &ret); err != nil {
+			return nil, fmt.Errorf("invalid statistics file %s: %w", p.path, err)
+		}
+	}
+	p.all = ret
+	return ret, nil
+}
+
+// StoreStatisticsPersister is a StatisticsPersister keeping statistics in a Store,
+// statistics of different instances are kept separately by hostname,
+// so the hostname specified by WithHostname should be stable across restarts.
+type StoreStatisticsPersister struct {
+	store Store
+}
+
+// NewStoreStatisticsPersister returns a StoreStatisticsPersister keeping statistics in the store.
+func NewStoreStatisticsPersister(store Store) *StoreStatisticsPersister {
+	return &StoreStatisticsPersister{
+		store: store,
+	}
+}
+
+func (p *StoreStatisticsPersister) key(c CronMeta, jobKey string) string {
+	return fmt.Sprintf("dcron:%s.%s/persisted/%s", c.Key(), jobKey, c.Hostname())
+}
+
+// Load implements StatisticsPersister.Load.
+func (p *StoreStatisticsPersister) Load(ctx context.Context, c CronMeta, jobKey string) (Statistics, bool, error) {
+	value, ok, err := p.store.Get(ctx, p.key(c, jobKey))
+	if err != nil || !ok {
+		return Statistics{}, false, err
+	}
+	var ret Statistics
+	if err := json.Unmarshal([]byte(value), &ret); err != nil {
+		return Statistics{}, false, fmt.Errorf("invalid statistics of %s: %w", jobKey, err)
+	}
+	return ret, true, nil
+}
+
+// Save implements StatisticsPersister.Save.
+func (p *StoreStatisticsPersister) Save(ctx context.Context, c CronMeta, statistics map[string]Statistics) error {
+	for jobKey, s := range statistics {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if err := p.store.Set(ctx, p.key(c, jobKey), string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadStatistics restores persisted statistics of the job,
+// errors are ignored, so the job could be added even if the persister is unavailable.
+func (c *Cron) loadStatistics(j *innerJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+	defer cancel()
+
+	if s, ok, err := c.persister.Load(ctx, c, j.key); err == nil && ok {
+		j.statistics.restore(s)
+	}
+}
+
+// persistStatistics saves statistics of the cron's all jobs with the persister.
+func (c *Cron) persistStatistics(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, persistTimeout)
+	defer cancel()
+
+	statistics := map[string]Statistics{}
+	for _, j := range c.allJobs() {
+		statistics[j.key] = j.statistics.snapshot()
+	}
+	return c.persister.Save(ctx, c, statistics)
+}
+
+// flushStatistics persists statistics periodically until the context is done.
+func (c *Cron) flushStatistics(ctx context.Context) {
+	ticker := time.NewTicker(c.persistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.persistStatistics(ctx)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package dcron
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+)
+
+func testStatisticsPersister(t *testing.T, p StatisticsPersister) {
+	ctx := context.Background()
+	c1 := NewCron(WithKey("test_cron1"), WithHostname("test_hostname"))
+	c2 := NewCron(WithKey("test_cron2"), WithHostname("test_hostname"))
+
+	if _, ok, err := p.Load(ctx, c1, "test"); err != nil || ok {
+		t.Fatalf("Load() = %v, %v", ok, err)
+	}
+
+	s1 := Statistics{TotalTask: 2, PassedTask: 1, FailedTask: 1}
+	s2 := Statistics{TotalTask: 3, MissedTask: 3}
+	if err := p.Save(ctx, c1, map[string]Statistics{"test": s1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Save(ctx, c2, map[string]Statistics{"test": s2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, ok, err := p.Load(ctx, c1, "test"); err != nil || !ok || got != s1 {
+		t.Fatalf("Load() = %+v, %v, %v, want %+v", got, ok, err, s1)
+	}
+	if got, ok, err := p.Load(ctx, c2, "test"); err != nil || !ok || got != s2 {
+		t.Fatalf("Load() = %+v, %v, %v, want %+v", got, ok, err, s2)
+	}
+	if _, ok, err := p.Load(ctx, c1, "unknown"); err != nil || ok {
+		t.Fatalf("Load() = %v, %v", ok, err)
+	}
+}
+
+func TestFileStatisticsPersister(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "statistics.json")
+	testStatisticsPersister(t, NewFileStatisticsPersister(path))
+
+	p := NewFileStatisticsPersister(path)
+	c := NewCron(WithKey("test_cron1"))
+	if _, ok, err := p.Load(context.Background(), c, "test"); err != nil || !ok {
+		t.Fatalf("Load() = %v, %v", ok, err)
+	}
+
+	if err := os.WriteFile(path, []byte("invalid"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// the file has been read
+	if _, ok, err := p.Load(context.Background(), c, "test"); err != nil || !ok {
+		t.Fatalf("Load() = %v, %v", ok, err)
+	}
+	if _, _, err := NewFileStatisticsPersister(path).Load(context.Background(), c, "test"); err == nil {
+		t.Fatal("Load() should fail")
+	}
+}
+
+// blockingStatisticsPersister is a StatisticsPersister blocking loading until released or timed out.
+type blockingStatisticsPersister struct {
+	loading chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingStatisticsPersister) Load(ctx context.Context, _ CronMeta, _ string) (Statistics, bool, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		return Statistics{}, false, errors.New("no deadline")
+	}
+	p.loading <- struct{}{}
+	select {
+	case <-ctx.Done():
+		return Statistics{}, false, ctx.Err()
+	case <-p.release:
+		return Statistics{TotalTask: 1}, true, nil
+	}
+}
+
+func (p *blockingStatisticsPersister) Save(_ context.Context, _ CronMeta, _ map[string]Statistics) error {
+	return nil
+}
+
+func TestWithStatisticsPersister_SlowLoad(t *testing.T) {
+	p := &blockingStatisticsPersister{
+		loading: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	c := NewCron(WithStatisticsPersister(p, 0))
+
+	added := make(chan error)
+	go func() {
+		added <- c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+			return nil
+		}))
+	}()
+	<-p.loading
+	// not blocked by the loading
+	if got := len(c.Jobs()); got != 0 {
+		t.Fatalf("len(Jobs()) = %v, want 0", got)
+	}
+	close(p.release)
+	if err := <-added; err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Statistics(); got != (Statistics{TotalTask: 1}) {
+		t.Fatalf("Statistics() = %+v", got)
+	}
+}
+
+func TestStoreStatisticsPersister(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	p := NewStoreStatisticsPersister(newMapStore(ctrl))
+	testStatisticsPersister(t, p)
+
+	// instances of the same cron
+	ctx := context.Background()
+	c1 := NewCron(WithKey("test_cron"), WithHostname("test_hostname1"))
+	c2 := NewCron(WithKey("test_cron"), WithHostname("test_hostname2"))
+	s1 := Statistics{TotalTask: 2, PassedTask: 2}
+	s2 := Statistics{TotalTask: 2, MissedTask: 2}
+	if err := p.Save(ctx, c1, map[string]Statistics{"test": s1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Save(ctx, c2, map[string]Statistics{"test": s2}); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok, err := p.Load(ctx, c1, "test"); err != nil || !ok || got != s1 {
+		t.Fatalf("Load() = %+v, %v, %v, want %+v", got, ok, err, s1)
+	}
+	if got, ok, err := p.Load(ctx, c2, "test"); err != nil || !ok || got != s2 {
+		t.Fatalf("Load() = %+v, %v, %v, want %+v", got, ok, err, s2)
+	}
+}
+
+func TestWithStatisticsPersister(t *testing.T) {
+	p := NewFileStatisticsPersister(filepath.Join(t.TempDir(), "statistics.json"))
+	newCron := func(interval time.Duration) *Cron {
+		c := NewCron(WithKey("test_cron"), WithStatisticsPersister(p, interval))
+		if err := c.AddJobs(NewJob("test", "0 0 0 1 1 *", func(ctx context.Context) error {
+			return nil
+		})); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	want := Statistics{TotalTask: 1, PassedTask: 1, TotalRun: 1, PassedRun: 1}
+
+	// saved on stopping
+	c := newCron(0)
+	c.Start()
+	if _, err := c.Trigger(context.Background(), "test"); err != nil {
+		t.Fatal(err)
+	}
+	<-c.Stop().Done()
+
+	c = newCron(10 * time.Millisecond)
+	if got := c.Statistics(); got != want {
+		t.Fatalf("Statistics() = %+v, want %+v", got, want)
+	}
+	if got := c.StatisticsDelta(); got != (Statistics{}) {
+		t.Fatalf("StatisticsDelta() = %+v, want zero", got)
+	}
+
+	// saved periodically
+	c.Start()
+	defer func() {
+		<-c.Stop().Done()
+	}()
+	if _, err := c.Trigger(context.Background(), "test"); err != nil {
+		t.Fatal(err)
+	}
+	want = want.Add(want)
+	for i := 0; ; i++ {
+		got, _, err := p.Load(context.Background(), c, "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == want {
+			break
+		}
+		if i > 100 {
+			t.Fatalf("Load() = %+v, want %+v", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
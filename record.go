@@ -0,0 +1,121 @@
+package dcron
+
+import (
+	"errors"
+	"time"
+)
+
+// TaskStatus is the status of a task.
+type TaskStatus string
+
+const (
+	TaskPassed   TaskStatus = "passed"   // The task was run by the instance and passed
+	TaskFailed   TaskStatus = "failed"   // The task was run by the instance and failed
+	TaskSkipped  TaskStatus = "skipped"  // The task was skipped
+	TaskMissed   TaskStatus = "missed"   // The task was run by other instances
+	TaskDeclined TaskStatus = "declined" // The task was declined by the instance
+)
+
+// TaskRecord is a serializable record of a task,
+// it could be encoded with encoding/json or encoding/gob.
+type TaskRecord struct {
+	Key         string        `json:"key"`         // Key of the task
+	CronKey     string        `json:"cron_key"`    // Key of the cron
+	JobKey      string        `json:"job_key"`     // Key of the job
+	Hostname    string        `json:"hostname"`    // Hostname of the instance which processed the task
+	PlanAt      time.Time     `json:"plan_at"`     // When the task was planned to run
+	BeginAt     *time.Time    `json:"begin_at"`    // When the task began to run, nil if it was not run by the instance
+	EndAt       *time.Time    `json:"end_at"`      // When the task finished running, nil if it was not run by the instance
+	Lag         time.Duration `json:"lag"`         // Duration from PlanAt to BeginAt, zero if it was not run by the instance
+	Duration    time.Duration `json:"duration"`    // Duration from BeginAt to EndAt, zero if it was not run by the instance
+	TriedTimes  int           `json:"tried_times"` // How many times the job was run
+	Error       string        `json:"error"`       // Message of the error returned by the last run, empty if it passed
+	Panicked    bool          `json:"panicked"`    // Whether the last run panicked
+	Skipped     bool          `json:"skipped"`     // Whether the task was skipped
+	Missed      bool          `json:"missed"`      // Whether the task was run by other instances
+	Declined    bool          `json:"declined"`    // Whether the task was declined by the instance
+	Manual      bool          `json:"manual"`      // Whether the task was created by Cron.Trigger or Cron.Backfill
+	Misfired    bool          `json:"misfired"`    // Whether the task was run by the MisfirePolicy of the job
+	Interrupted bool          `json:"interrupted"` // Whether the task was interrupted by stopping the cron
+}
+
+// Status returns the status of the task.
+func (r TaskRecord) Status() TaskStatus {
+	switch {
+	case r.Skipped:
+		return TaskSkipped
+	case r.Declined:
+		return TaskDeclined
+	case r.Missed:
+		return TaskMissed
+	case r.Error != "":
+		return TaskFailed
+	}
+	return TaskPassed
+}
+
+// Record returns a serializable record of the task.
+func (t Task) Record() TaskRecord {
+	ret := TaskRecord{
+		Key:         t.Key,
+		PlanAt:      t.PlanAt,
+		BeginAt:     t.BeginAt,
+		EndAt:       t.EndAt,
+		TriedTimes:  t.TriedTimes,
+		Skipped:     t.Skipped,
+		Missed:      t.Missed,
+		Declined:    t.Declined,
+		Manual:      t.Manual,
+		Misfired:    t.Misfired,
+		Interrupted: t.Interrupted,
+	}
+	if t.Cron != nil {
+		ret.CronKey = t.Cron.Key()
+		ret.Hostname = t.Cron.Hostname()
+	}
+	if t.Job != nil {
+		ret.JobKey = t.Job.Key()
+	}
+	if t.Return != nil {
+		var panicErr *PanicError
+		ret.Error = t.Return.Error()
+		ret.Panicked = errors.As(t.Return, &panicErr)
+	}
+	ret.setDurations()
+	return ret
+}
+
+// setDurations fills Lag and Duration according to the times.
+func (r *TaskRecord) setDurations() {
+	r.Lag, r.Duration = 0, 0
+	if r.BeginAt != nil {
+		r.Lag = r.BeginAt.Sub(r.PlanAt)
+		if r.EndAt != nil {
+			r.Duration = r.EndAt.Sub(*r.BeginAt)
+		}
+	}
+}
+
+// task restores a Task from the record, without Cron and Job.
+func (r TaskRecord) task() Task {
+	ret := Task{
+		Key:         r.Key,
+		PlanAt:      r.PlanAt,
+		BeginAt:     r.BeginAt,
+		EndAt:       r.EndAt,
+		Skipped:     r.Skipped,
+		Missed:      r.Missed,
+		Declined:    r.Declined,
+		TriedTimes:  r.TriedTimes,
+		Manual:      r.Manual,
+		Misfired:    r.Misfired,
+		Interrupted: r.Interrupted,
+	}
+	switch {
+	case r.Panicked:
+		ret.Return = &PanicError{Value: r.Error}
+	case r.Error != "":
+		ret.Return = errors.New(r.Error)
+	}
+	return ret
+}
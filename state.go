@@ -0,0 +1,33 @@
+package dcron
+
+// State is the lifecycle state of a cron.
+type State int
+
+const (
+	// StateCreated means the cron has been created but never started.
+	StateCreated State = iota
+	// StateRunning means the cron is scheduling and acquiring tasks.
+	StateRunning
+	// StateDraining means the cron stops acquiring tasks and is waiting for running ones.
+	StateDraining
+	// StateStopped means the cron has been stopped, and it could be started again.
+	StateStopped
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// StateHook represents the function could be called when the state of a cron changes.
+type StateHook func(c CronMeta, from, to State)
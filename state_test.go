@@ -0,0 +1,44 @@
+package dcron
+
+import "testing"
+
+func TestState_String(t *testing.T) {
+	tests := []struct {
+		name string
+		s    State
+		want string
+	}{
+		{
+			name: "created",
+			s:    StateCreated,
+			want: "created",
+		},
+		{
+			name: "running",
+			s:    StateRunning,
+			want: "running",
+		},
+		{
+			name: "draining",
+			s:    StateDraining,
+			want: "draining",
+		},
+		{
+			name: "stopped",
+			s:    StateStopped,
+			want: "stopped",
+		},
+		{
+			name: "unknown",
+			s:    State(-1),
+			want: "unknown",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
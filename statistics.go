@@ -1,12 +1,24 @@
 package dcron
 
+import (
+	"sync"
+	"time"
+)
+
+// rollingMinutes and rollingHours are how many minutes and hours of recent statistics are kept.
+const (
+	rollingMinutes = 60
+	rollingHours   = 24
+)
+
 // Statistics records statistics info for a cron or a job.
 type Statistics struct {
-	TotalTask   int64 // Total count of tasks processed
-	PassedTask  int64 // Number of tasks successfully executed
-	FailedTask  int64 // Number of tasks that failed during execution due to errors
-	SkippedTask int64 // Number of tasks skipped due to BeforeFunc returning true
-	MissedTask  int64 // Number of tasks executed by other instances
+	TotalTask    int64 // Total count of tasks processed
+	PassedTask   int64 // Number of tasks successfully executed
+	FailedTask   int64 // Number of tasks that failed during execution due to errors
+	SkippedTask  int64 // Number of tasks skipped due to BeforeFunc returning true or the job being paused
+	MissedTask   int64 // Number of tasks executed by other instances
+	DeclinedTask int64 // Number of tasks declined due to the instance failing the admission check, having no free slot or draining
 
 	TotalRun   int64 // Total count of execution runs
 	PassedRun  int64 // Number of successfully executed runs
@@ -21,9 +33,130 @@ func (s Statistics) Add(delta Statistics) Statistics {
 	s.FailedTask += delta.FailedTask
 	s.SkippedTask += delta.SkippedTask
 	s.MissedTask += delta.MissedTask
+	s.DeclinedTask += delta.DeclinedTask
 	s.TotalRun += delta.TotalRun
 	s.PassedRun += delta.PassedRun
 	s.FailedRun += delta.FailedRun
 	s.RetriedRun += delta.RetriedRun
 	return s
 }
+
+// Sub return a new Statistics with the other subtracted,
+// it could be used to calculate how statistics have changed between two snapshots.
+func (s Statistics) Sub(other Statistics) Statistics {
+	s.TotalTask -= other.TotalTask
+	s.PassedTask -= other.PassedTask
+	s.FailedTask -= other.FailedTask
+	s.SkippedTask -= other.SkippedTask
+	s.MissedTask -= other.MissedTask
+	s.DeclinedTask -= other.DeclinedTask
+	s.TotalRun -= other.TotalRun
+	s.PassedRun -= other.PassedRun
+	s.FailedRun -= other.FailedRun
+	s.RetriedRun -= other.RetriedRun
+	return s
+}
+
+// statisticsRecorder records Statistics, it is safe for concurrent use,
+// and its snapshots are consistent.
+type statisticsRecorder struct {
+	mu       sync.Mutex
+	current  Statistics
+	reported Statistics                       // the snapshot returned by the last call of delta
+	minutes  [rollingMinutes]statisticsBucket // ring buffer of statistics per minute
+	hours    [rollingHours]statisticsBucket   // ring buffer of statistics per hour
+}
+
+// statisticsBucket is statistics of a minute or an hour.
+type statisticsBucket struct {
+	index int64 // Unix minutes or hours
+	Statistics
+}
+
+// addBucket adds delta to the bucket of index in the ring buffer.
+func addBucket(buckets []statisticsBucket, index int64, delta Statistics) {
+	b := &buckets[index%int64(len(buckets))]
+	if b.index != index {
+		*b = statisticsBucket{index: index}
+	}
+	b.Statistics = b.Add(delta)
+}
+
+// sumBuckets returns the sum of the last n buckets up to index in the ring buffer.
+func sumBuckets(buckets []statisticsBucket, index, n int64) Statistics {
+	if n > int64(len(buckets)) {
+		n = int64(len(buckets))
+	}
+	ret := Statistics{}
+	for _, b := range buckets {
+		if b.index > index-n && b.index <= index {
+			ret = ret.Add(b.Statistics)
+		}
+	}
+	return ret
+}
+
+func (r *statisticsRecorder) add(delta Statistics) {
+	r.addAt(delta, time.Now())
+}
+
+func (r *statisticsRecorder) addAt(delta Statistics, t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = r.current.Add(delta)
+	addBucket(r.minutes[:], t.Unix()/60, delta)
+	addBucket(r.hours[:], t.Unix()/3600, delta)
+}
+
+// recent returns statistics of the recent window,
+// with minute granularity up to an hour and hour granularity up to 24 hours.
+func (r *statisticsRecorder) recent(window time.Duration) Statistics {
+	return r.recentAt(window, time.Now())
+}
+
+func (r *statisticsRecorder) recentAt(window time.Duration, t time.Time) Statistics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if window <= time.Hour {
+		return sumBuckets(r.minutes[:], t.Unix()/60, int64((window+time.Minute-1)/time.Minute))
+	}
+	return sumBuckets(r.hours[:], t.Unix()/3600, int64((window+time.Hour-1)/time.Hour))
+}
+
+func (r *statisticsRecorder) snapshot() Statistics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.current
+}
+
+// restore replaces the statistics with persisted ones, which are not counted in recent statistics or delta.
+func (r *statisticsRecorder) restore(s Statistics) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = s
+	r.reported = s
+}
+
+func (r *statisticsRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.current = Statistics{}
+	r.reported = Statistics{}
+	r.minutes = [rollingMinutes]statisticsBucket{}
+	r.hours = [rollingHours]statisticsBucket{}
+}
+
+// delta returns how the statistics have changed since the last call.
+func (r *statisticsRecorder) delta() Statistics {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret := r.current.Sub(r.reported)
+	r.reported = r.current
+	return ret
+}
@@ -2,20 +2,23 @@ package dcron
 
 import (
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestStatistics_Add(t *testing.T) {
 	type fields struct {
-		TotalTask   int64
-		PassedTask  int64
-		FailedTask  int64
-		SkippedTask int64
-		MissedTask  int64
-		TotalRun    int64
-		PassedRun   int64
-		FailedRun   int64
-		RetriedRun  int64
+		TotalTask    int64
+		PassedTask   int64
+		FailedTask   int64
+		SkippedTask  int64
+		MissedTask   int64
+		DeclinedTask int64
+		TotalRun     int64
+		PassedRun    int64
+		FailedRun    int64
+		RetriedRun   int64
 	}
 	type args struct {
 		delta Statistics
@@ -29,54 +32,58 @@ func TestStatistics_Add(t *testing.T) {
 		{
 			name: "regular",
 			fields: fields{
-				TotalTask:   1,
-				PassedTask:  2,
-				FailedTask:  3,
-				SkippedTask: 4,
-				MissedTask:  5,
-				TotalRun:    6,
-				PassedRun:   7,
-				FailedRun:   8,
-				RetriedRun:  9,
+				TotalTask:    1,
+				PassedTask:   2,
+				FailedTask:   3,
+				SkippedTask:  4,
+				MissedTask:   5,
+				DeclinedTask: 6,
+				TotalRun:     6,
+				PassedRun:    7,
+				FailedRun:    8,
+				RetriedRun:   9,
 			},
 			args: args{
 				delta: Statistics{
-					TotalTask:   1,
-					PassedTask:  2,
-					FailedTask:  3,
-					SkippedTask: 4,
-					MissedTask:  5,
-					TotalRun:    6,
-					PassedRun:   7,
-					FailedRun:   8,
-					RetriedRun:  9,
+					TotalTask:    1,
+					PassedTask:   2,
+					FailedTask:   3,
+					SkippedTask:  4,
+					MissedTask:   5,
+					DeclinedTask: 6,
+					TotalRun:     6,
+					PassedRun:    7,
+					FailedRun:    8,
+					RetriedRun:   9,
 				},
 			},
 			want: Statistics{
-				TotalTask:   2,
-				PassedTask:  4,
-				FailedTask:  6,
-				SkippedTask: 8,
-				MissedTask:  10,
-				TotalRun:    12,
-				PassedRun:   14,
-				FailedRun:   16,
-				RetriedRun:  18,
+				TotalTask:    2,
+				PassedTask:   4,
+				FailedTask:   6,
+				SkippedTask:  8,
+				MissedTask:   10,
+				DeclinedTask: 12,
+				TotalRun:     12,
+				PassedRun:    14,
+				FailedRun:    16,
+				RetriedRun:   18,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Statistics{
-				TotalTask:   tt.fields.TotalTask,
-				PassedTask:  tt.fields.PassedTask,
-				FailedTask:  tt.fields.FailedTask,
-				SkippedTask: tt.fields.SkippedTask,
-				MissedTask:  tt.fields.MissedTask,
-				TotalRun:    tt.fields.TotalRun,
-				PassedRun:   tt.fields.PassedRun,
-				FailedRun:   tt.fields.FailedRun,
-				RetriedRun:  tt.fields.RetriedRun,
+				TotalTask:    tt.fields.TotalTask,
+				PassedTask:   tt.fields.PassedTask,
+				FailedTask:   tt.fields.FailedTask,
+				SkippedTask:  tt.fields.SkippedTask,
+				MissedTask:   tt.fields.MissedTask,
+				DeclinedTask: tt.fields.DeclinedTask,
+				TotalRun:     tt.fields.TotalRun,
+				PassedRun:    tt.fields.PassedRun,
+				FailedRun:    tt.fields.FailedRun,
+				RetriedRun:   tt.fields.RetriedRun,
 			}
 			if got := s.Add(tt.args.delta); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Add() = %v, want %v", got, tt.want)
@@ -84,3 +91,179 @@ func TestStatistics_Add(t *testing.T) {
 		})
 	}
 }
+
+func TestStatistics_Sub(t *testing.T) {
+	type args struct {
+		other Statistics
+	}
+	tests := []struct {
+		name string
+		s    Statistics
+		args args
+		want Statistics
+	}{
+		{
+			name: "regular",
+			s: Statistics{
+				TotalTask:    2,
+				PassedTask:   4,
+				FailedTask:   6,
+				SkippedTask:  8,
+				MissedTask:   10,
+				DeclinedTask: 12,
+				TotalRun:     12,
+				PassedRun:    14,
+				FailedRun:    16,
+				RetriedRun:   18,
+			},
+			args: args{
+				other: Statistics{
+					TotalTask:    1,
+					PassedTask:   2,
+					FailedTask:   3,
+					SkippedTask:  4,
+					MissedTask:   5,
+					DeclinedTask: 6,
+					TotalRun:     6,
+					PassedRun:    7,
+					FailedRun:    8,
+					RetriedRun:   9,
+				},
+			},
+			want: Statistics{
+				TotalTask:    1,
+				PassedTask:   2,
+				FailedTask:   3,
+				SkippedTask:  4,
+				MissedTask:   5,
+				DeclinedTask: 6,
+				TotalRun:     6,
+				PassedRun:    7,
+				FailedRun:    8,
+				RetriedRun:   9,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.s.Sub(tt.args.other); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sub() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_statisticsRecorder(t *testing.T) {
+	r := &statisticsRecorder{}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				r.add(Statistics{TotalTask: 1, PassedTask: 1})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if s := r.snapshot(); s.TotalTask != s.PassedTask {
+					t.Errorf("inconsistent snapshot: %+v", s)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := r.snapshot(); got.TotalTask != 1000 {
+		t.Fatalf("snapshot() = %+v", got)
+	}
+	if got := r.delta(); got.TotalTask != 1000 {
+		t.Fatalf("delta() = %+v", got)
+	}
+	r.add(Statistics{TotalTask: 1})
+	if got := r.delta(); got != (Statistics{TotalTask: 1}) {
+		t.Fatalf("delta() = %+v", got)
+	}
+	if got := r.delta(); got != (Statistics{}) {
+		t.Fatalf("delta() = %+v", got)
+	}
+
+	r.add(Statistics{TotalTask: 1})
+	r.reset()
+	if got := r.snapshot(); got != (Statistics{}) {
+		t.Fatalf("snapshot() = %+v", got)
+	}
+	r.add(Statistics{TotalTask: 1})
+	if got := r.delta(); got != (Statistics{TotalTask: 1}) {
+		t.Fatalf("delta() = %+v", got)
+	}
+}
+
+func Test_statisticsRecorder_recent(t *testing.T) {
+	now := time.Date(2023, 10, 13, 12, 0, 30, 0, time.UTC)
+	r := &statisticsRecorder{}
+	r.addAt(Statistics{TotalTask: 1}, now.Add(-25*time.Hour))
+	r.addAt(Statistics{TotalTask: 1, FailedTask: 1}, now.Add(-2*time.Hour))
+	r.addAt(Statistics{TotalTask: 1}, now.Add(-30*time.Minute))
+	r.addAt(Statistics{TotalTask: 1, PassedTask: 1}, now.Add(-3*time.Minute))
+	r.addAt(Statistics{TotalTask: 1, PassedTask: 1}, now)
+
+	tests := []struct {
+		name   string
+		window time.Duration
+		want   Statistics
+	}{
+		{
+			name:   "5 minutes",
+			window: 5 * time.Minute,
+			want:   Statistics{TotalTask: 2, PassedTask: 2},
+		},
+		{
+			name:   "1 hour",
+			window: time.Hour,
+			want:   Statistics{TotalTask: 3, PassedTask: 2},
+		},
+		{
+			name:   "2 hours",
+			window: 2 * time.Hour,
+			want:   Statistics{TotalTask: 3, PassedTask: 2},
+		},
+		{
+			name:   "90 minutes",
+			window: 90 * time.Minute,
+			want:   Statistics{TotalTask: 3, PassedTask: 2},
+		},
+		{
+			name:   "24 hours",
+			window: 24 * time.Hour,
+			want:   Statistics{TotalTask: 4, PassedTask: 2, FailedTask: 1},
+		},
+		{
+			name:   "longer than 24 hours",
+			window: 48 * time.Hour,
+			want:   Statistics{TotalTask: 4, PassedTask: 2, FailedTask: 1},
+		},
+		{
+			name:   "zero",
+			window: 0,
+			want:   Statistics{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.recentAt(tt.window, now); got != tt.want {
+				t.Errorf("recentAt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := r.snapshot(); got.TotalTask != 5 {
+		t.Fatalf("snapshot() = %+v", got)
+	}
+	r.reset()
+	if got := r.recentAt(time.Hour, now); got != (Statistics{}) {
+		t.Fatalf("recentAt() = %+v, want zero", got)
+	}
+}
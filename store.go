@@ -0,0 +1,18 @@
+package dcron
+
+import "context"
+
+//go:generate go get go.uber.org/mock/mockgen
+//go:generate go run go.uber.org/mock/mockgen -source=store.go -destination mock_dcron/store.go
+//go:generate go mod tidy
+
+// Store provides distributed key/value storage for dcron,
+// it can be implemented easily via Redis/SQL and so on.
+type Store interface {
+	// Get returns the value of the key, or returns false if the key is not existed.
+	Get(ctx context.Context, key string) (value string, ok bool, err error)
+	// Set stores the key/value, it should be kept until being deleted or overwritten.
+	Set(ctx context.Context, key, value string) error
+	// Delete removes the key, or does nothing if the key is not existed.
+	Delete(ctx context.Context, key string) error
+}
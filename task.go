@@ -1,7 +1,10 @@
 package dcron
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"encoding/json"
 	"time"
 )
 
@@ -22,9 +25,20 @@ type Task struct {
 	Return     error
 	Skipped    bool
 	Missed     bool
+	Declined   bool
 	TriedTimes int
+	// Manual means the task is created by Cron.Trigger or Cron.Backfill rather than scheduled.
+	Manual bool
+	// Misfired means the task was missed while the cron was down, and is run by the MisfirePolicy of the job.
+	Misfired bool
+	// Interrupted means the task failed since its context was canceled by stopping the cron,
+	// rather than reaching its deadline.
+	Interrupted bool
 }
 
+// TaskHook represents the function could be called when a task of any job finishes.
+type TaskHook func(task Task)
+
 // TaskFromContext extracts a Task from a context,
 // it is useful inner the Run function.
 func TaskFromContext(ctx context.Context) (Task, bool) {
@@ -34,3 +48,39 @@ func TaskFromContext(ctx context.Context) (Task, bool) {
 	task, ok := ctx.Value(keyContextTask).(Task)
 	return task, ok
 }
+
+// MarshalJSON implements json.Marshaler, the task is encoded as its TaskRecord.
+func (t Task) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Record())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, the task is decoded from a TaskRecord,
+// Cron and Job will be nil, and Return will keep only the message of the error.
+func (t *Task) UnmarshalJSON(data []byte) error {
+	var record TaskRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	*t = record.task()
+	return nil
+}
+
+// GobEncode implements gob.GobEncoder, the task is encoded as its TaskRecord.
+func (t Task) GobEncode() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(t.Record()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder, the task is decoded from a TaskRecord,
+// Cron and Job will be nil, and Return will keep only the message of the error.
+func (t *Task) GobDecode(data []byte) error {
+	var record TaskRecord
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&record); err != nil {
+		return err
+	}
+	*t = record.task()
+	return nil
+}
@@ -0,0 +1,447 @@
+package dcron
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultQueryLimit = 100
+	pruneInterval     = 10 * time.Minute
+	// saveTimeout limits how long saving a record could take,
+	// so a stalled TaskStore will not block tasks, and Drain or Shutdown waiting for them.
+	saveTimeout = 5 * time.Second
+)
+
+// TaskStore stores records of tasks, it can be used to audit what ran where.
+type TaskStore interface {
+	// Save stores the record of a task.
+	Save(ctx context.Context, record TaskRecord) error
+	// Query returns records matching the query, in descending order of plan time.
+	Query(ctx context.Context, query TaskQuery) (TaskPage, error)
+	// Prune removes records planned before the time, and returns how many records are removed.
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// TaskQuery describes which records to query, zero fields match any records.
+type TaskQuery struct {
+	CronKey  string     // Key of the cron
+	JobKey   string     // Key of the job
+	Status   TaskStatus // Status of the task
+	Hostname string     // Hostname of the instance which processed the task
+	From     time.Time  // Records planned at or after the time
+	To       time.Time  // Records planned before the time
+	Cursor   string     // TaskPage.NextCursor of the previous page, empty for the first page
+	Limit    int        // Max number of records in the page, 100 if it is not positive
+}
+
+// TaskPage is a page of records.
+type TaskPage struct {
+	Records    []TaskRecord
+	NextCursor string // Cursor to query the next page, empty if there are no more records
+}
+
+func (q TaskQuery) limit() int {
+	if q.Limit <= 0 {
+		return defaultQueryLimit
+	}
+	return q.Limit
+}
+
+// match reports whether the record matches the query, except the cursor.
+func (q TaskQuery) match(record TaskRecord) bool {
+	return (q.CronKey == "" || record.CronKey == q.CronKey) &&
+		(q.JobKey == "" || record.JobKey == q.JobKey) &&
+		(q.Status == "" || record.Status() == q.Status) &&
+		(q.Hostname == "" || record.Hostname == q.Hostname) &&
+		(q.From.IsZero() || !record.PlanAt.Before(q.From)) &&
+		(q.To.IsZero() || record.PlanAt.Before(q.To))
+}
+
+// taskCursor is the position of the last record of a page.
+type taskCursor struct {
+	planAt int64
+	id     string
+}
+
+func (c taskCursor) String() string {
+	return fmt.Sprintf("%d/%s", c.planAt, c.id)
+}
+
+func parseTaskCursor(s string) (taskCursor, error) {
+	planAt, id, ok := strings.Cut(s, "/")
+	if !ok {
+		return taskCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	v, err := strconv.ParseInt(planAt, 10, 64)
+	if err != nil {
+		return taskCursor{}, fmt.Errorf("invalid cursor %q: %w", s, err)
+	}
+	return taskCursor{planAt: v, id: id}, nil
+}
+
+// MemoryTaskStore is a TaskStore keeping records in memory.
+type MemoryTaskStore struct {
+	mu      sync.RWMutex
+	seq     int64
+	records []memoryTaskRecord
+}
+
+type memoryTaskRecord struct {
+	TaskRecord
+	seq int64
+}
+
+// NewMemoryTaskStore returns an empty MemoryTaskStore.
+func NewMemoryTaskStore() *MemoryTaskStore {
+	return &MemoryTaskStore{}
+}
+
+// Save implements TaskStore.Save.
+func (s *MemoryTaskStore) Save(_ context.Context, record TaskRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	s.records = append(s.records, memoryTaskRecord{
+		TaskRecord: record,
+		seq:        s.seq,
+	})
+	return nil
+}
+
+// Query implements TaskStore.Query.
+func (s *MemoryTaskStore) Query(_ context.Context, query TaskQuery) (TaskPage, error) {
+	var cursor *taskCursor
+	if query.Cursor != "" {
+		v, err := parseTaskCursor(query.Cursor)
+		if err != nil {
+			return TaskPage{}, err
+		}
+		cursor = &v
+	}
+
+	s.mu.RLock()
+	var matched []memoryTaskRecord
+	for _, record := range s.records {
+		if query.match(record.TaskRecord) {
+			matched = append(matched, record)
+		}
+	}
+	s.mu.RUnlock()
+
+	var cursorSeq int64
+	if cursor != nil {
+		cursorSeq, _ = strconv.ParseInt(cursor.id, 10, 64)
+	}
+	less := func(a memoryTaskRecord, planAt, seq int64) bool {
+		if v := a.PlanAt.UnixNano(); v != planAt {
+			return v < planAt
+		}
+		return a.seq < seq
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(matched[j], matched[i].PlanAt.UnixNano(), matched[i].seq)
+	})
+
+	ret := TaskPage{}
+	var last memoryTaskRecord
+	for _, record := range matched {
+		if cursor != nil && !less(record, cursor.planAt, cursorSeq) {
+			continue
+		}
+		if len(ret.Records) == query.limit() {
+			ret.NextCursor = taskCursor{
+				planAt: last.PlanAt.UnixNano(),
+				id:     strconv.FormatInt(last.seq, 10),
+			}.String()
+			break
+		}
+		ret.Records = append(ret.Records, record.TaskRecord)
+		last = record
+	}
+	return ret, nil
+}
+
+// Prune implements TaskStore.Prune.
+func (s *MemoryTaskStore) Prune(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept []memoryTaskRecord
+	for _, record := range s.records {
+		if !record.PlanAt.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	pruned := int64(len(s.records) - len(kept))
+	s.records = kept
+	return pruned, nil
+}
+
+// Records returns all records in order of saving.
+func (s *MemoryTaskStore) Records() []TaskRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ret := make([]TaskRecord, 0, len(s.records))
+	for _, record := range s.records {
+		ret = append(ret, record.TaskRecord)
+	}
+	return ret
+}
+
+// SQLTaskStoreOption represents a modification to the default behavior of a SQLTaskStore.
+type SQLTaskStoreOption func(s *SQLTaskStore)
+
+// WithPlaceholder specifies how to write the n-th (starting from 1) placeholder of statements,
+// it is "?" by default, and could be "$n" for PostgreSQL.
+func WithPlaceholder(placeholder func(n int) string) SQLTaskStoreOption {
+	return func(s *SQLTaskStore) {
+		s.placeholder = placeholder
+	}
+}
+
+// SQLTaskStore is a TaskStore keeping records in a SQL database.
+// Times are stored as Unix nanoseconds, and booleans are stored as 0 or 1,
+// so it works with most SQL databases, like SQLite, PostgreSQL and MySQL.
+type SQLTaskStore struct {
+	db          *sql.DB
+	table       string
+	placeholder func(n int) string
+}
+
+// NewSQLTaskStore returns a SQLTaskStore keeping records in the table,
+// the table and its index could be created by SQLTaskStore.CreateTable and SQLTaskStore.CreateIndex.
+func NewSQLTaskStore(db *sql.DB, table string, options ...SQLTaskStoreOption) *SQLTaskStore {
+	s := &SQLTaskStore{
+		db:    db,
+		table: table,
+		placeholder: func(n int) string {
+			return "?"
+		},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// sqlTaskColumns are columns of the table in order, except the id.
+var sqlTaskColumns = []string{
+	"task_key", "cron_key", "job_key", "hostname",
+	"plan_at", "begin_at", "end_at", "tried_times", "error", "panicked",
+	"skipped", "missed", "declined", "manual", "misfired", "interrupted",
+}
+
+// CreateTable creates the table if it is not existed.
+func (s *SQLTaskStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id VARCHAR(32) NOT NULL PRIMARY KEY,
+	task_key VARCHAR(255) NOT NULL,
+	cron_key VARCHAR(255) NOT NULL,
+	job_key VARCHAR(255) NOT NULL,
+	hostname VARCHAR(255) NOT NULL,
+	plan_at BIGINT NOT NULL,
+	begin_at BIGINT,
+	end_at BIGINT,
+	tried_times INTEGER NOT NULL,
+	error TEXT NOT NULL,
+	panicked SMALLINT NOT NULL,
+	skipped SMALLINT NOT NULL,
+	missed SMALLINT NOT NULL,
+	declined SMALLINT NOT NULL,
+	manual SMALLINT NOT NULL,
+	misfired SMALLINT NOT NULL,
+	interrupted SMALLINT NOT NULL
+)`, s.table))
+	return err
+}
+
+// CreateIndex creates the index for querying records by plan time if it is not existed.
+// It uses "CREATE INDEX IF NOT EXISTS", which is not supported by MySQL,
+// so the index should be created by "CREATE INDEX <table>_plan_at ON <table> (plan_at, id)" there.
+func (s *SQLTaskStore) CreateIndex(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_plan_at ON %s (plan_at, id)", s.table, s.table))
+	return err
+}
+
+// Save implements TaskStore.Save.
+func (s *SQLTaskStore) Save(ctx context.Context, record TaskRecord) error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	placeholders := make([]string, len(sqlTaskColumns)+1)
+	for i := range placeholders {
+		placeholders[i] = s.placeholder(i + 1)
+	}
+	_, err := s.db.ExecContext(ctx,
+		fmt.Sprintf("INSERT INTO %s (id, %s) VALUES (%s)", s.table, strings.Join(sqlTaskColumns, ", "), strings.Join(placeholders, ", ")),
+		hex.EncodeToString(id),
+		record.Key, record.CronKey, record.JobKey, record.Hostname,
+		record.PlanAt.UnixNano(), sqlTime(record.BeginAt), sqlTime(record.EndAt), record.TriedTimes, record.Error, sqlBool(record.Panicked),
+		sqlBool(record.Skipped), sqlBool(record.Missed), sqlBool(record.Declined), sqlBool(record.Manual), sqlBool(record.Misfired), sqlBool(record.Interrupted),
+	)
+	return err
+}
+
+// Query implements TaskStore.Query.
+func (s *SQLTaskStore) Query(ctx context.Context, query TaskQuery) (TaskPage, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	where := func(condition string, values ...any) {
+		for _, v := range values {
+			args = append(args, v)
+			condition = strings.Replace(condition, "?", s.placeholder(len(args)), 1)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	if query.CronKey != "" {
+		where("cron_key = ?", query.CronKey)
+	}
+	if query.JobKey != "" {
+		where("job_key = ?", query.JobKey)
+	}
+	switch query.Status {
+	case "":
+	case TaskPassed:
+		where("skipped = 0 AND missed = 0 AND declined = 0 AND error = ''")
+	case TaskFailed:
+		where("skipped = 0 AND missed = 0 AND declined = 0 AND error <> ''")
+	case TaskSkipped:
+		where("skipped = 1")
+	case TaskMissed:
+		where("missed = 1")
+	case TaskDeclined:
+		where("declined = 1")
+	default:
+		return TaskPage{}, fmt.Errorf("unknown status %q", query.Status)
+	}
+	if query.Hostname != "" {
+		where("hostname = ?", query.Hostname)
+	}
+	if !query.From.IsZero() {
+		where("plan_at >= ?", query.From.UnixNano())
+	}
+	if !query.To.IsZero() {
+		where("plan_at < ?", query.To.UnixNano())
+	}
+	if query.Cursor != "" {
+		cursor, err := parseTaskCursor(query.Cursor)
+		if err != nil {
+			return TaskPage{}, err
+		}
+		where("(plan_at < ? OR (plan_at = ? AND id < ?))", cursor.planAt, cursor.planAt, cursor.id)
+	}
+
+	statement := fmt.Sprintf("SELECT id, %s FROM %s", strings.Join(sqlTaskColumns, ", "), s.table)
+	if len(conditions) != 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	statement += fmt.Sprintf(" ORDER BY plan_at DESC, id DESC LIMIT %d", query.limit()+1)
+
+	rows, err := s.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return TaskPage{}, err
+	}
+	defer rows.Close()
+
+	ret := TaskPage{}
+	var last taskCursor
+	for rows.Next() {
+		if len(ret.Records) == query.limit() {
+			ret.NextCursor = last.String()
+			break
+		}
+
+		var (
+			id                                                                 string
+			record                                                             TaskRecord
+			planAt                                                             int64
+			beginAt, endAt                                                     sql.NullInt64
+			panicked, skipped, missed, declined, manual, misfired, interrupted int
+		)
+		if err := rows.Scan(
+			&id,
+			&record.Key, &record.CronKey, &record.JobKey, &record.Hostname,
+			&planAt, &beginAt, &endAt, &record.TriedTimes, &record.Error, &panicked,
+			&skipped, &missed, &declined, &manual, &misfired, &interrupted,
+		); err != nil {
+			return TaskPage{}, err
+		}
+		record.PlanAt = time.Unix(0, planAt)
+		record.BeginAt = fromSQLTime(beginAt)
+		record.EndAt = fromSQLTime(endAt)
+		record.Panicked = panicked != 0
+		record.Skipped = skipped != 0
+		record.Missed = missed != 0
+		record.Declined = declined != 0
+		record.Manual = manual != 0
+		record.Misfired = misfired != 0
+		record.Interrupted = interrupted != 0
+		record.setDurations()
+
+		ret.Records = append(ret.Records, record)
+		last = taskCursor{planAt: planAt, id: id}
+	}
+	return ret, rows.Err()
+}
+
+// Prune implements TaskStore.Prune.
+func (s *SQLTaskStore) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE plan_at < %s", s.table, s.placeholder(1)), before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func sqlTime(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+func fromSQLTime(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.Unix(0, v.Int64)
+	return &t
+}
+
+func sqlBool(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// pruneTasks removes expired records of tasks periodically until the context is done.
+func (c *Cron) pruneTasks(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = c.taskStore.Prune(ctx, time.Now().Add(-c.taskRetention))
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package dcron
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func testTaskRecords() []TaskRecord {
+	planAt := time.Date(2023, 10, 13, 11, 40, 0, 0, time.UTC)
+	beginAt := planAt.Add(time.Millisecond)
+	endAt := planAt.Add(time.Second)
+	return []TaskRecord{
+		{
+			Key:        "dcron:test_cron.test@1697197200",
+			CronKey:    "test_cron",
+			JobKey:     "test",
+			Hostname:   "test_hostname",
+			PlanAt:     planAt,
+			BeginAt:    &beginAt,
+			EndAt:      &endAt,
+			TriedTimes: 2,
+			Error:      "should retry",
+		},
+		{
+			Key:      "dcron:test_cron.test@1697197200",
+			CronKey:  "test_cron",
+			JobKey:   "test",
+			Hostname: "other_hostname",
+			PlanAt:   planAt,
+			Missed:   true,
+		},
+	}
+}
+
+func TestMemoryTaskStore(t *testing.T) {
+	s := NewMemoryTaskStore()
+	records := testTaskRecords()
+	for _, record := range records {
+		if err := s.Save(context.Background(), record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := s.Records(); !reflect.DeepEqual(got, records) {
+		t.Fatalf("Records() = %v, want %v", got, records)
+	}
+}
+
+func TestSQLTaskStore(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx := context.Background()
+	s := NewSQLTaskStore(db, "dcron_tasks")
+	if err := s.CreateTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateIndex(ctx); err != nil {
+		t.Fatal(err)
+	}
+	records := testTaskRecords()
+	for _, record := range records {
+		if err := s.Save(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		key      string
+		hostname string
+		beginAt  sql.NullInt64
+		tried    int
+		errMsg   string
+		missed   int
+	)
+	row := db.QueryRowContext(ctx, "SELECT task_key, hostname, begin_at, tried_times, error, missed FROM dcron_tasks WHERE hostname = ?", "test_hostname")
+	if err := row.Scan(&key, &hostname, &beginAt, &tried, &errMsg, &missed); err != nil {
+		t.Fatal(err)
+	}
+	if key != records[0].Key || beginAt.Int64 != records[0].BeginAt.UnixNano() || tried != 2 || errMsg != "should retry" || missed != 0 {
+		t.Fatalf("unexpected row: %v %v %v %v %v %v", key, hostname, beginAt, tried, errMsg, missed)
+	}
+
+	row = db.QueryRowContext(ctx, "SELECT begin_at, missed FROM dcron_tasks WHERE hostname = ?", "other_hostname")
+	if err := row.Scan(&beginAt, &missed); err != nil {
+		t.Fatal(err)
+	}
+	if beginAt.Valid || missed != 1 {
+		t.Fatalf("unexpected row: %v %v", beginAt, missed)
+	}
+}
+
+func TestWithTaskStore(t *testing.T) {
+	s := NewMemoryTaskStore()
+	c := NewCron(WithKey("test_cron"), WithHostname("test_hostname"), WithTaskStore(s))
+	if err := c.AddJobs(
+		NewJob("test1", "* * * * * *", func(ctx context.Context) error {
+			return errors.New("failed")
+		}),
+		NewJob("test2", "* * * * * *", nil, WithBeforeFunc(func(task Task) (skip bool) {
+			return true
+		})),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Trigger(context.Background(), "test1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Trigger(context.Background(), "test2"); err != nil {
+		t.Fatal(err)
+	}
+
+	records := s.Records()
+	if len(records) != 2 {
+		t.Fatalf("len(records) = %v, want 2", len(records))
+	}
+	if r := records[0]; r.JobKey != "test1" || r.CronKey != "test_cron" || r.Hostname != "test_hostname" ||
+		r.Error != "failed" || r.TriedTimes != 1 || r.BeginAt == nil || r.EndAt == nil || !r.Manual {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+	if r := records[1]; r.JobKey != "test2" || !r.Skipped || r.BeginAt != nil {
+		t.Fatalf("unexpected record: %+v", r)
+	}
+}
+
+func testTaskStoreQuery(t *testing.T, s TaskStore) {
+	ctx := context.Background()
+	planAt := time.Date(2023, 10, 13, 11, 40, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		record := TaskRecord{
+			CronKey:  "test_cron",
+			JobKey:   "test1",
+			Hostname: "test_hostname",
+			PlanAt:   planAt.Add(time.Duration(i) * time.Second),
+		}
+		if i%2 == 1 {
+			record.JobKey = "test2"
+			record.Error = "failed"
+		}
+		if i%5 == 0 {
+			record.Hostname = "other_hostname"
+			record.Missed = true
+		}
+		if err := s.Save(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	planTimes := func(records []TaskRecord) []int {
+		var ret []int
+		for _, record := range records {
+			ret = append(ret, int(record.PlanAt.Sub(planAt)/time.Second))
+		}
+		return ret
+	}
+
+	tests := []struct {
+		name  string
+		query TaskQuery
+		want  []int
+	}{
+		{
+			name:  "all",
+			query: TaskQuery{},
+			want:  []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+		},
+		{
+			name:  "job key",
+			query: TaskQuery{JobKey: "test1"},
+			want:  []int{8, 6, 4, 2, 0},
+		},
+		{
+			name:  "passed",
+			query: TaskQuery{Status: TaskPassed},
+			want:  []int{8, 6, 4, 2},
+		},
+		{
+			name:  "failed",
+			query: TaskQuery{Status: TaskFailed},
+			want:  []int{9, 7, 3, 1},
+		},
+		{
+			name:  "missed",
+			query: TaskQuery{Status: TaskMissed},
+			want:  []int{5, 0},
+		},
+		{
+			name:  "hostname",
+			query: TaskQuery{Hostname: "other_hostname"},
+			want:  []int{5, 0},
+		},
+		{
+			name:  "time range",
+			query: TaskQuery{From: planAt.Add(2 * time.Second), To: planAt.Add(5 * time.Second)},
+			want:  []int{4, 3, 2},
+		},
+		{
+			name:  "combined",
+			query: TaskQuery{JobKey: "test2", Status: TaskFailed, From: planAt.Add(3 * time.Second)},
+			want:  []int{9, 7, 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := s.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := planTimes(page.Records); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Query() = %v, want %v", got, tt.want)
+			}
+			if page.NextCursor != "" {
+				t.Fatalf("NextCursor = %v, want empty", page.NextCursor)
+			}
+		})
+	}
+
+	t.Run("pagination", func(t *testing.T) {
+		var got []int
+		query := TaskQuery{Limit: 3}
+		for pages := 0; ; pages++ {
+			if pages > 10 {
+				t.Fatal("too many pages")
+			}
+			page, err := s.Query(ctx, query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(page.Records) > 3 {
+				t.Fatalf("len(Records) = %v, want <= 3", len(page.Records))
+			}
+			got = append(got, planTimes(page.Records)...)
+			if page.NextCursor == "" {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		if want := []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Query() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		if _, err := s.Query(ctx, TaskQuery{Cursor: "invalid"}); err == nil {
+			t.Fatal("want error")
+		}
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		pruned, err := s.Prune(ctx, planAt.Add(4*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pruned != 4 {
+			t.Fatalf("Prune() = %v, want 4", pruned)
+		}
+		page, err := s.Query(ctx, TaskQuery{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := planTimes(page.Records), []int{9, 8, 7, 6, 5, 4}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Query() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("cron key", func(t *testing.T) {
+		if err := s.Save(ctx, TaskRecord{
+			CronKey:  "other_cron",
+			JobKey:   "test1",
+			Hostname: "test_hostname",
+			PlanAt:   planAt.Add(7 * time.Second),
+		}); err != nil {
+			t.Fatal(err)
+		}
+		tests := []struct {
+			query TaskQuery
+			want  []int
+		}{
+			{query: TaskQuery{JobKey: "test1"}, want: []int{8, 7, 6, 4}},
+			{query: TaskQuery{CronKey: "test_cron", JobKey: "test1"}, want: []int{8, 6, 4}},
+			{query: TaskQuery{CronKey: "other_cron"}, want: []int{7}},
+		}
+		for _, tt := range tests {
+			page, err := s.Query(ctx, tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := planTimes(page.Records); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Query(%+v) = %v, want %v", tt.query, got, tt.want)
+			}
+		}
+	})
+}
+
+func TestMemoryTaskStore_Query(t *testing.T) {
+	testTaskStoreQuery(t, NewMemoryTaskStore())
+}
+
+func TestSQLTaskStore_Query(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	s := NewSQLTaskStore(db, "dcron_tasks")
+	// created twice to check they are skipped if existed
+	for i := 0; i < 2; i++ {
+		if err := s.CreateTable(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.CreateIndex(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testTaskStoreQuery(t, s)
+}
+
+func TestTaskRecord_Status(t *testing.T) {
+	tests := []struct {
+		name   string
+		record TaskRecord
+		want   TaskStatus
+	}{
+		{name: "passed", record: TaskRecord{}, want: TaskPassed},
+		{name: "failed", record: TaskRecord{Error: "failed"}, want: TaskFailed},
+		{name: "skipped", record: TaskRecord{Skipped: true}, want: TaskSkipped},
+		{name: "missed", record: TaskRecord{Missed: true}, want: TaskMissed},
+		{name: "declined", record: TaskRecord{Declined: true}, want: TaskDeclined},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.record.Status(); got != tt.want {
+				t.Fatalf("Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithTaskRetention(t *testing.T) {
+	s := NewMemoryTaskStore()
+	ctx := context.Background()
+	if err := s.Save(ctx, TaskRecord{JobKey: "test", PlanAt: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(ctx, TaskRecord{JobKey: "test", PlanAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCron(WithTaskStore(s), WithTaskRetention(time.Hour))
+	c.Start()
+	defer c.Stop()
+
+	for i := 0; len(s.Records()) != 1; i++ {
+		if i > 100 {
+			t.Fatalf("len(records) = %v, want 1", len(s.Records()))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// deadlineTaskStore is a TaskStore which only saves records with a bounded context.
+type deadlineTaskStore struct {
+	*MemoryTaskStore
+}
+
+func (s deadlineTaskStore) Save(ctx context.Context, record TaskRecord) error {
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > saveTimeout {
+		return errors.New("unbounded context")
+	}
+	return s.MemoryTaskStore.Save(ctx, record)
+}
+
+func TestWithTaskStore_Timeout(t *testing.T) {
+	s := deadlineTaskStore{NewMemoryTaskStore()}
+	c := NewCron(WithTaskStore(s))
+	if err := c.AddJobs(NewJob("test", "* * * * * *", func(ctx context.Context) error {
+		return nil
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Trigger(context.Background(), "test"); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(s.Records()); got != 1 {
+		t.Fatalf("len(records) = %v, want 1", got)
+	}
+}
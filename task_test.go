@@ -1,9 +1,14 @@
 package dcron
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestTaskInContext(t *testing.T) {
@@ -58,3 +63,171 @@ func TestTaskInContext(t *testing.T) {
 		})
 	}
 }
+
+func testTasks() []Task {
+	c := NewCron(WithKey("test_cron"), WithHostname("test_hostname"))
+	j := &innerJob{key: "test_job"}
+	planAt := time.Date(2023, 10, 13, 11, 40, 0, 0, time.UTC)
+	beginAt := planAt.Add(time.Millisecond)
+	endAt := planAt.Add(time.Second)
+	return []Task{
+		{
+			Key:        "dcron:test_cron.test_job@1697197200",
+			Cron:       c,
+			Job:        j,
+			PlanAt:     planAt,
+			BeginAt:    &beginAt,
+			EndAt:      &endAt,
+			Return:     errors.New("failed"),
+			TriedTimes: 2,
+			Manual:     true,
+		},
+		{
+			Key:        "dcron:test_cron.test_job@1697197200",
+			Cron:       c,
+			Job:        j,
+			PlanAt:     planAt,
+			BeginAt:    &beginAt,
+			EndAt:      &endAt,
+			Return:     &PanicError{Value: "not happy", Stack: []byte("stack")},
+			TriedTimes: 1,
+		},
+		{
+			Key:     "dcron:test_cron.test_job@1697197200",
+			PlanAt:  planAt,
+			Skipped: true,
+		},
+	}
+}
+
+func TestTask_Record(t *testing.T) {
+	tasks := testTasks()
+	beginAt := *tasks[0].BeginAt
+	endAt := *tasks[0].EndAt
+	tests := []struct {
+		name string
+		task Task
+		want TaskRecord
+	}{
+		{
+			name: "failed",
+			task: tasks[0],
+			want: TaskRecord{
+				Key:        "dcron:test_cron.test_job@1697197200",
+				CronKey:    "test_cron",
+				JobKey:     "test_job",
+				Hostname:   "test_hostname",
+				PlanAt:     tasks[0].PlanAt,
+				BeginAt:    &beginAt,
+				EndAt:      &endAt,
+				Lag:        time.Millisecond,
+				Duration:   999 * time.Millisecond,
+				TriedTimes: 2,
+				Error:      "failed",
+				Manual:     true,
+			},
+		},
+		{
+			name: "panicked",
+			task: tasks[1],
+			want: TaskRecord{
+				Key:        "dcron:test_cron.test_job@1697197200",
+				CronKey:    "test_cron",
+				JobKey:     "test_job",
+				Hostname:   "test_hostname",
+				PlanAt:     tasks[1].PlanAt,
+				BeginAt:    &beginAt,
+				EndAt:      &endAt,
+				Lag:        time.Millisecond,
+				Duration:   999 * time.Millisecond,
+				TriedTimes: 1,
+				Error:      "not happy: stack",
+				Panicked:   true,
+			},
+		},
+		{
+			name: "skipped",
+			task: tasks[2],
+			want: TaskRecord{
+				Key:     "dcron:test_cron.test_job@1697197200",
+				PlanAt:  tasks[2].PlanAt,
+				Skipped: true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.Record(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Record() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTask_JSON(t *testing.T) {
+	for _, task := range testTasks() {
+		data, err := json.Marshal(task)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var record TaskRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			t.Fatal(err)
+		}
+		if want := task.Record(); !reflect.DeepEqual(record, want) {
+			t.Errorf("json.Unmarshal() = %+v, want %+v", record, want)
+		}
+
+		var got Task
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+		checkDecodedTask(t, got, task)
+	}
+}
+
+func TestTask_Gob(t *testing.T) {
+	for _, task := range testTasks() {
+		buf := &bytes.Buffer{}
+		if err := gob.NewEncoder(buf).Encode(task); err != nil {
+			t.Fatal(err)
+		}
+		var got Task
+		if err := gob.NewDecoder(buf).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		checkDecodedTask(t, got, task)
+
+		buf.Reset()
+		if err := gob.NewEncoder(buf).Encode(task.Record()); err != nil {
+			t.Fatal(err)
+		}
+		var record TaskRecord
+		if err := gob.NewDecoder(buf).Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		if want := task.Record(); !reflect.DeepEqual(record, want) {
+			t.Errorf("gob.Decode() = %+v, want %+v", record, want)
+		}
+	}
+}
+
+func checkDecodedTask(t *testing.T, got, want Task) {
+	t.Helper()
+	if got.Cron != nil || got.Job != nil {
+		t.Errorf("decoded task should not have Cron or Job: %+v", got)
+	}
+	if (got.Return == nil) != (want.Return == nil) || got.Return != nil && got.Return.Error() != want.Return.Error() {
+		t.Errorf("Return = %v, want %v", got.Return, want.Return)
+	}
+	var panicErr *PanicError
+	if errors.As(got.Return, &panicErr) != errors.As(want.Return, &panicErr) {
+		t.Errorf("Return = %#v, want %#v", got.Return, want.Return)
+	}
+	gotRecord, wantRecord := got.Record(), want.Record()
+	wantRecord.CronKey, wantRecord.JobKey, wantRecord.Hostname = "", "", ""
+	if !reflect.DeepEqual(gotRecord, wantRecord) {
+		t.Errorf("decoded task = %+v, want %+v", gotRecord, wantRecord)
+	}
+}
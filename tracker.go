@@ -0,0 +1,104 @@
+package dcron
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// taskTracker keeps track of tasks in progress on a cron instance.
+type taskTracker struct {
+	sync.Mutex
+
+	draining bool
+	nextID   uint64
+	running  map[uint64]string
+	idle     chan struct{}
+}
+
+// begin records a task in progress, or returns false if the tracker is draining.
+func (t *taskTracker) begin(key string) (uint64, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.draining {
+		return 0, false
+	}
+	if t.running == nil {
+		t.running = map[uint64]string{}
+	}
+	t.nextID++
+	t.running[t.nextID] = key
+	return t.nextID, true
+}
+
+// end records a task has finished.
+func (t *taskTracker) end(id uint64) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.running, id)
+	if len(t.running) == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+}
+
+// count returns the number of tasks in progress.
+func (t *taskTracker) count() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return len(t.running)
+}
+
+// keys returns the keys of tasks in progress.
+func (t *taskTracker) keys() []string {
+	t.Lock()
+	defer t.Unlock()
+
+	var ret []string
+	for _, key := range t.running {
+		ret = append(ret, key)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// setDraining stops or resumes accepting new tasks, and returns the number of tasks in progress.
+func (t *taskTracker) setDraining(draining bool) int {
+	t.Lock()
+	defer t.Unlock()
+
+	t.draining = draining
+	return len(t.running)
+}
+
+// isDraining reports whether the tracker stops accepting new tasks.
+func (t *taskTracker) isDraining() bool {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.draining
+}
+
+// wait blocks until no task is in progress or the context is done.
+func (t *taskTracker) wait(ctx context.Context) error {
+	t.Lock()
+	if len(t.running) == 0 {
+		t.Unlock()
+		return nil
+	}
+	if t.idle == nil {
+		t.idle = make(chan struct{})
+	}
+	idle := t.idle
+	t.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,61 @@
+package dcron
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_taskTracker(t *testing.T) {
+	tr := &taskTracker{}
+
+	if err := tr.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	id1, ok := tr.begin("task1")
+	if !ok {
+		t.Fatal("begin() should pass")
+	}
+	id2, ok := tr.begin("task2")
+	if !ok {
+		t.Fatal("begin() should pass")
+	}
+	if got := tr.count(); got != 2 {
+		t.Fatalf("count() = %v, want 2", got)
+	}
+	if got := tr.keys(); !reflect.DeepEqual(got, []string{"task1", "task2"}) {
+		t.Fatalf("keys() = %v, want [task1 task2]", got)
+	}
+
+	if got := tr.setDraining(true); got != 2 {
+		t.Fatalf("setDraining() = %v, want 2", got)
+	}
+	if _, ok := tr.begin("task3"); ok {
+		t.Fatal("begin() should fail when draining")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := tr.wait(ctx); err == nil {
+		t.Fatal("wait() should time out")
+	}
+
+	go func() {
+		tr.end(id1)
+		time.Sleep(100 * time.Millisecond)
+		tr.end(id2)
+	}()
+	if err := tr.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := tr.count(); got != 0 {
+		t.Fatalf("count() = %v, want 0", got)
+	}
+
+	tr.setDraining(false)
+	if _, ok := tr.begin("task3"); !ok {
+		t.Fatal("begin() should pass after resuming")
+	}
+}